@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/breach"
+)
+
+const usage = `Usage: go run ./cmd/breachfilter -in <corpus> -out <filter> [-min-count N] [-fp rate]
+
+Builds the Bloom filter read with BREACHED_PASSWORDS_SOURCE=bloom from a HIBP corpus,
+either a directory of range files (00000.txt ... FFFFF.txt) or one file of "HASH:COUNT" lines.`
+
+func main() {
+	in := flag.String("in", "", "HIBP range directory or HASH:COUNT file")
+	out := flag.String("out", "", "Bloom filter file to write")
+	minCount := flag.Int("min-count", 1, "only add hashes seen at least this many times, must match BREACHED_PASSWORDS_MIN_COUNT")
+	fpRate := flag.Float64("fp", 0.001, "false positive rate")
+	flag.Usage = func() { fmt.Fprintln(os.Stderr, usage); flag.PrintDefaults() }
+	flag.Parse()
+
+	if *in == "" || *out == "" || *minCount < 1 || *fpRate <= 0 || *fpRate >= 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// First pass sizes the filter, the second fills it
+	var n uint64
+	err := breach.Walk(*in, func(hash string, count int) error {
+		if count >= *minCount {
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to read corpus: %v", err)
+	}
+
+	filter := breach.NewBloom(n, *fpRate, *minCount)
+	err = breach.Walk(*in, func(hash string, count int) error {
+		if count >= *minCount {
+			return filter.AddHash(hash)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to read corpus: %v", err)
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		log.Fatalf("Failed to create filter file: %v", err)
+	}
+	size, err := filter.WriteTo(file)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		log.Fatalf("Failed to write filter file: %v", err)
+	}
+
+	fmt.Printf("Wrote %d hashes seen at least %d times to %s (%d bytes)\n", filter.Entries, *minCount, *out, size)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+)
+
+const usage = `Usage: go run ./cmd/keys <command>
+
+Commands:
+  list          list the signing keys
+  rotate        generate a new active signing key
+  retire <kid>  stop a key from verifying tokens`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+
+	// connect to database
+	db := database.Connect()
+	defer database.Close()
+
+	switch os.Args[1] {
+	case "list":
+		signingKeys, err := keys.List(db)
+		if err != nil {
+			log.Fatalf("Failed to list signing keys: %v", err)
+		}
+		out, _ := json.MarshalIndent(signingKeys, "", "  ")
+		fmt.Println(string(out))
+	case "rotate":
+		key, err := keys.Rotate(db, 0)
+		if err != nil {
+			log.Fatalf("Failed to rotate signing key: %v", err)
+		}
+		fmt.Println("New active signing key:", key.ID)
+	case "retire":
+		if len(os.Args) < 3 {
+			fmt.Println(usage)
+			os.Exit(2)
+		}
+		if err := keys.Retire(db, os.Args[2]); err != nil {
+			log.Fatalf("Failed to retire signing key: %v", err)
+		}
+		fmt.Println("Retired signing key:", os.Args[2])
+	default:
+		fmt.Println(usage)
+		os.Exit(2)
+	}
+}
@@ -8,10 +8,20 @@ import (
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 	"github.com/sagorsarker04/Developer-Assignment/internal/http/routes"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
 )
 
 func main() {
+	// Fail fast if the JWT signing keys are missing or invalid
+	keys.GetRing()
+	keys.StartRotationSchedule(database.Connect())
+
+	// Fail fast if the banned password list or the breached password corpus cannot be read
+	password.GetPolicy()
+
 	router := mux.NewRouter()
 
 	routes.SetupRoutes(router)
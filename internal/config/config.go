@@ -3,8 +3,11 @@ package config
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,13 +16,21 @@ import (
 
 // Config holds all configuration for the applicationb
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Admin    AdminConfig
-	Email    EmailConfig
-	Server   ServerConfig
-	Password PasswordConfig
+	App       AppConfig
+	Database  DatabaseConfig
+	JWT       JWTConfig
+	Admin     AdminConfig
+	Email     EmailConfig
+	Server    ServerConfig
+	Password  PasswordConfig
+	Cookie    CookieConfig
+	MFA       MFAConfig
+	MagicLink MagicLinkConfig
+	OAuth     OAuthConfig
+	Tokens    AccessTokenConfig
+	WebAuthn  WebAuthnConfig
+	Lockout   LockoutConfig
+	RateLimit RateLimitConfig
 }
 
 // AppConfig holds application-specific configuration
@@ -40,7 +51,39 @@ type DatabaseConfig struct {
 // JWTConfig holds JWT configuration
 type JWTConfig struct {
 	Secret string
-	Expiry time.Duration
+	// Algorithm is one of HS256, RS256, ES256 or EdDSA
+	Algorithm string
+	// PrivateKeyPath points to the PEM encoded private key used by the asymmetric algorithms
+	PrivateKeyPath string
+	// KeyID is the kid header of issued tokens, derived from the key when empty
+	KeyID string
+	// KeyRotationInterval is how often a new signing key is generated, 0 disables scheduled rotation
+	KeyRotationInterval time.Duration
+	// KeyRetention is how long a rotated key keeps verifying tokens
+	KeyRetention time.Duration
+
+	Expiry        time.Duration
+	RefreshExpiry time.Duration
+	// TokenLookup lists where access tokens are read from, in order of precedence ("header", "cookie")
+	TokenLookup []string
+
+	// Issuer is the iss claim of issued tokens, tokens from any other issuer are rejected
+	Issuer string
+	// Audience is the aud claim of access tokens, other token purposes derive theirs from it
+	Audience string
+	// AllowedAlgorithms lists the alg headers accepted when validating tokens
+	AllowedAlgorithms []string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat
+	Leeway time.Duration
+}
+
+// CookieConfig holds the settings of the auth cookies
+type CookieConfig struct {
+	Name        string
+	RefreshName string
+	Domain      string
+	Secure      bool
+	SameSite    http.SameSite
 }
 
 // AdminConfig holds system admin information
@@ -66,10 +109,112 @@ type EmailConfig struct {
 // ServerConfig holds server configuration
 type ServerConfig struct {
 	Port int
+	// TrustProxyHeaders makes the client IP come from X-Forwarded-For / X-Real-IP.
+	// Only enable it behind a reverse proxy that overwrites these headers.
+	TrustProxyHeaders bool
+}
+
+// MFAConfig holds two-factor authentication configuration
+type MFAConfig struct {
+	// Issuer is the account issuer shown in authenticator apps
+	Issuer string
+	// PendingTTL is how long the token between the password step and the second factor stays valid
+	PendingTTL time.Duration
+}
+
+// MagicLinkConfig holds the passwordless email login settings
+type MagicLinkConfig struct {
+	// Enabled registers the /auth/magic-link endpoints
+	Enabled bool
+	// TTL is how long an emailed link and code stay valid
+	TTL time.Duration
+	// URL is the frontend page the link points to, the token is appended as ?token=
+	URL string
+	// MaxCodeAttempts is how many wrong codes burn an emailed code
+	MaxCodeAttempts int
+}
+
+// OAuthConfig holds the settings of the OAuth2 / OpenID Connect provider.
+// Its endpoints are published under JWT_ISSUER, which has to be the public URL of the service.
+type OAuthConfig struct {
+	// CodeTTL is how long an authorization code can be traded for tokens
+	CodeTTL time.Duration
+	// AccessTokenTTL is the lifetime of the access tokens issued to clients
+	AccessTokenTTL time.Duration
+	// IDTokenTTL is the lifetime of ID tokens
+	IDTokenTTL time.Duration
+}
+
+// AccessTokenConfig holds the limits of personal access tokens
+type AccessTokenConfig struct {
+	// DefaultTTL is the lifetime of a token created without expires_in_days
+	DefaultTTL time.Duration
+	// MaxTTL is the longest lifetime a user can pick
+	MaxTTL time.Duration
+}
+
+// WebAuthnConfig holds the relying party settings of passkey ceremonies
+type WebAuthnConfig struct {
+	// RPID is the domain credentials are scoped to, it must match the origins
+	RPID   string
+	RPName string
+	// Origins lists the exact origins (scheme://host[:port]) the browser may report
+	Origins []string
+	// Timeout is how long a challenge stays valid
+	Timeout time.Duration
+}
+
+// LockoutConfig holds the brute-force protection policy of the login
+type LockoutConfig struct {
+	// Store is where failure counters are kept, "postgres" or "memory"
+	Store string
+	// MaxAttempts is the number of consecutive failures that lock an account, 0 disables account lockout
+	MaxAttempts int
+	// IPMaxAttempts is the number of consecutive failures that lock a client IP, 0 disables IP lockout
+	IPMaxAttempts int
+	// Duration is how long a locked account or IP stays locked
+	Duration time.Duration
+	// BackoffBase is the wait after the first failure, it doubles with every further failure up to BackoffMax
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// Window is how long failures are remembered after the last one
+	Window time.Duration
+}
+
+// RateLimitConfig holds the rate limits of the endpoints that send email
+type RateLimitConfig struct {
+	// Store is where token buckets are kept, "memory" or "postgres" for multi-instance deployments
+	Store                string
+	Register             RateLimitRule
+	ResendVerification   RateLimitRule
+	PasswordResetRequest RateLimitRule
+	MagicLinkRequest     RateLimitRule
+}
+
+// RateLimitRule allows Requests per Period for every key, Keys lists what a client is identified by ("ip", "email", "user").
+// Zero Requests disables the rule.
+type RateLimitRule struct {
+	Requests int
+	Period   time.Duration
+	Keys     []string
 }
 
 type PasswordConfig struct {
-	PasswordResetTTL time.Duration
+	PasswordResetTTL  time.Duration
+	HashAlgorithm     string // argon2id or bcrypt, used for new hashes
+	Argon2Memory      uint32 // KiB
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+	MinLength         int      // in characters
+	MaxLength         int      // in characters
+	RequiredClasses   []string // lower, upper, digit, symbol
+	BannedListPath    string   // file with one banned password per line, added to the built-in list
+	CheckSimilarity   bool     // reject passwords containing the username or email
+	History           int      // number of previous passwords that cannot be reused, 0 disables
+	BreachSource      string   // hibp or bloom, empty disables breached password screening
+	BreachPath        string   // HIBP range directory or Bloom filter file
+	BreachMinCount    int      // occurrences in the corpus that make a password breached
 }
 
 var (
@@ -86,12 +231,56 @@ func LoadConfig() (*Config, error) {
 	dbPort, _ := strconv.Atoi(getEnv("DB_PORT", "5432"))
 
 	// Parse JWT expiry
-	jwtExpiryStr := getEnv("JWT_EXPIRY", "24h")
+	jwtExpiryStr := getEnv("JWT_EXPIRY", "15m")
 	jwtExpiry, err := time.ParseDuration(jwtExpiryStr)
 	if err != nil {
 		log.Fatalf("Invalid JWT_EXPIRY value: %v", err)
 	}
 
+	// Parse refresh token expiry
+	refreshExpiry, err := time.ParseDuration(getEnv("JWT_REFRESH_EXPIRY", "168h"))
+	if err != nil {
+		log.Fatalf("Invalid JWT_REFRESH_EXPIRY value: %v", err)
+	}
+
+	// Parse signing key rotation schedule
+	keyRotationInterval, err := time.ParseDuration(getEnv("JWT_KEY_ROTATION_INTERVAL", "0s"))
+	if err != nil {
+		log.Fatalf("Invalid JWT_KEY_ROTATION_INTERVAL value: %v", err)
+	}
+	keyRetention, err := time.ParseDuration(getEnv("JWT_KEY_RETENTION", "24h"))
+	if err != nil {
+		log.Fatalf("Invalid JWT_KEY_RETENTION value: %v", err)
+	}
+
+	// Parse token lookup order
+	tokenLookup, err := parseTokenLookup(getEnv("TOKEN_LOOKUP", "header,cookie"))
+	if err != nil {
+		log.Fatalf("Invalid TOKEN_LOOKUP value: %v", err)
+	}
+
+	// Parse token validation settings
+	jwtAlgorithm := getEnv("JWT_ALGORITHM", "HS256")
+	allowedAlgorithms, err := parseAlgorithms(getEnv("JWT_ALLOWED_ALGORITHMS", jwtAlgorithm))
+	if err != nil {
+		log.Fatalf("Invalid JWT_ALLOWED_ALGORITHMS value: %v", err)
+	}
+	// Tokens signed with an algorithm the service does not accept would fail on every request
+	if !slices.Contains(allowedAlgorithms, jwtAlgorithm) {
+		log.Fatalf("JWT_ALGORITHM %q must be listed in JWT_ALLOWED_ALGORITHMS %v", jwtAlgorithm, allowedAlgorithms)
+	}
+	jwtLeeway, err := time.ParseDuration(getEnv("JWT_LEEWAY", "30s"))
+	if err != nil {
+		log.Fatalf("Invalid JWT_LEEWAY value: %v", err)
+	}
+
+	// Parse cookie settings
+	cookieSecure, _ := strconv.ParseBool(getEnv("COOKIE_SECURE", "false"))
+	cookieSameSite, err := parseSameSite(getEnv("COOKIE_SAMESITE", "lax"))
+	if err != nil {
+		log.Fatalf("Invalid COOKIE_SAMESITE value: %v", err)
+	}
+
 	// Parse email port
 	emailPort, _ := strconv.Atoi(getEnv("EMAIL_PORT", "587"))
 
@@ -106,14 +295,157 @@ func LoadConfig() (*Config, error) {
 		log.Fatalf("Invalid VERIFICATION_TOKEN_TTL value: %v", err)
 	}
 
-	passwordResetTTL := getEnv("PASSWORD_RESET_TTL","5m")
+	passwordResetTTL := getEnv("PASSWORD_RESET_TTL", "5m")
 	passwordTTL, err := time.ParseDuration(passwordResetTTL)
 	if err != nil {
 		log.Fatalf("Invalid password reset token %v", err)
 	}
 
+	// Parse password hashing parameters
+	hashAlgorithm := strings.ToLower(getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"))
+	if hashAlgorithm != "argon2id" && hashAlgorithm != "bcrypt" {
+		log.Fatalf("Invalid PASSWORD_HASH_ALGORITHM value: %q", hashAlgorithm)
+	}
+	argon2Memory, err := strconv.ParseUint(getEnv("PASSWORD_ARGON2_MEMORY", "65536"), 10, 32)
+	if err != nil || argon2Memory < 8 {
+		log.Fatalf("Invalid PASSWORD_ARGON2_MEMORY value: %v", err)
+	}
+	argon2Iterations, err := strconv.ParseUint(getEnv("PASSWORD_ARGON2_ITERATIONS", "3"), 10, 32)
+	if err != nil || argon2Iterations < 1 {
+		log.Fatalf("Invalid PASSWORD_ARGON2_ITERATIONS value: %v", err)
+	}
+	argon2Parallelism, err := strconv.ParseUint(getEnv("PASSWORD_ARGON2_PARALLELISM", "2"), 10, 8)
+	if err != nil || argon2Parallelism < 1 {
+		log.Fatalf("Invalid PASSWORD_ARGON2_PARALLELISM value: %v", err)
+	}
+	bcryptCost, err := strconv.Atoi(getEnv("PASSWORD_BCRYPT_COST", "12"))
+	if err != nil || bcryptCost < 4 || bcryptCost > 31 {
+		log.Fatalf("Invalid PASSWORD_BCRYPT_COST value: %v", err)
+	}
+
+	// Parse password policy
+	passwordMinLength, err := strconv.Atoi(getEnv("PASSWORD_MIN_LENGTH", "8"))
+	if err != nil || passwordMinLength < 1 {
+		log.Fatalf("Invalid PASSWORD_MIN_LENGTH value: %v", err)
+	}
+	passwordMaxLength, err := strconv.Atoi(getEnv("PASSWORD_MAX_LENGTH", "64"))
+	if err != nil || passwordMaxLength < passwordMinLength {
+		log.Fatalf("Invalid PASSWORD_MAX_LENGTH value: %v", err)
+	}
+	passwordClasses, err := parsePasswordClasses(getEnv("PASSWORD_REQUIRED_CLASSES", ""))
+	if err != nil {
+		log.Fatalf("Invalid PASSWORD_REQUIRED_CLASSES value: %v", err)
+	}
+	passwordCheckSimilarity, _ := strconv.ParseBool(getEnv("PASSWORD_CHECK_SIMILARITY", "true"))
+	passwordHistory, err := strconv.Atoi(getEnv("PASSWORD_HISTORY", "5"))
+	if err != nil || passwordHistory < 0 {
+		log.Fatalf("Invalid PASSWORD_HISTORY value: %v", err)
+	}
+
+	// Parse breached password screening
+	breachSource := strings.ToLower(getEnv("BREACHED_PASSWORDS_SOURCE", ""))
+	breachPath := getEnv("BREACHED_PASSWORDS_PATH", "")
+	if breachSource != "" && breachSource != "hibp" && breachSource != "bloom" {
+		log.Fatalf("Invalid BREACHED_PASSWORDS_SOURCE value: %q", breachSource)
+	}
+	if breachSource != "" && breachPath == "" {
+		log.Fatalf("BREACHED_PASSWORDS_PATH is required when BREACHED_PASSWORDS_SOURCE is set")
+	}
+	breachMinCount, err := strconv.Atoi(getEnv("BREACHED_PASSWORDS_MIN_COUNT", "1"))
+	if err != nil || breachMinCount < 1 {
+		log.Fatalf("Invalid BREACHED_PASSWORDS_MIN_COUNT value: %v", err)
+	}
+
+	// Parse MFA pending token TTL
+	mfaPendingTTL, err := time.ParseDuration(getEnv("MFA_PENDING_TTL", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid MFA_PENDING_TTL value: %v", err)
+	}
+
+	// Parse magic link settings
+	magicLinkEnabled, _ := strconv.ParseBool(getEnv("MAGIC_LINK_ENABLED", "false"))
+	magicLinkTTL, err := time.ParseDuration(getEnv("MAGIC_LINK_TTL", "10m"))
+	if err != nil {
+		log.Fatalf("Invalid MAGIC_LINK_TTL value: %v", err)
+	}
+	magicLinkMaxAttempts, err := strconv.Atoi(getEnv("MAGIC_LINK_MAX_CODE_ATTEMPTS", "5"))
+	if err != nil || magicLinkMaxAttempts < 1 {
+		log.Fatalf("Invalid MAGIC_LINK_MAX_CODE_ATTEMPTS value: %v", err)
+	}
+
+	// Parse OAuth2 / OpenID Connect provider settings
+	oauthCodeTTL, err := time.ParseDuration(getEnv("OAUTH_CODE_TTL", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid OAUTH_CODE_TTL value: %v", err)
+	}
+	oauthAccessTokenTTL, err := time.ParseDuration(getEnv("OAUTH_ACCESS_TOKEN_TTL", "15m"))
+	if err != nil {
+		log.Fatalf("Invalid OAUTH_ACCESS_TOKEN_TTL value: %v", err)
+	}
+	oauthIDTokenTTL, err := time.ParseDuration(getEnv("OAUTH_ID_TOKEN_TTL", "1h"))
+	if err != nil {
+		log.Fatalf("Invalid OAUTH_ID_TOKEN_TTL value: %v", err)
+	}
+
+	// Parse personal access token limits
+	patDefaultTTL, err := time.ParseDuration(getEnv("PAT_DEFAULT_TTL", "720h"))
+	if err != nil {
+		log.Fatalf("Invalid PAT_DEFAULT_TTL value: %v", err)
+	}
+	patMaxTTL, err := time.ParseDuration(getEnv("PAT_MAX_TTL", "8760h"))
+	if err != nil || patMaxTTL < patDefaultTTL {
+		log.Fatalf("Invalid PAT_MAX_TTL value, it must be at least PAT_DEFAULT_TTL: %v", err)
+	}
+
+	// Parse WebAuthn settings
+	webAuthnTimeout, err := time.ParseDuration(getEnv("WEBAUTHN_TIMEOUT", "5m"))
+	if err != nil {
+		log.Fatalf("Invalid WEBAUTHN_TIMEOUT value: %v", err)
+	}
+	var webAuthnOrigins []string
+	for _, origin := range strings.Split(getEnv("WEBAUTHN_ORIGINS", "http://localhost:5173,http://localhost:8080"), ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			webAuthnOrigins = append(webAuthnOrigins, origin)
+		}
+	}
+
+	// Parse lockout policy
+	lockoutMaxAttempts, _ := strconv.Atoi(getEnv("LOCKOUT_MAX_ATTEMPTS", "5"))
+	lockoutIPMaxAttempts, _ := strconv.Atoi(getEnv("LOCKOUT_IP_MAX_ATTEMPTS", "20"))
+	lockoutDuration, err := time.ParseDuration(getEnv("LOCKOUT_DURATION", "15m"))
+	if err != nil {
+		log.Fatalf("Invalid LOCKOUT_DURATION value: %v", err)
+	}
+	lockoutBackoffBase, err := time.ParseDuration(getEnv("LOCKOUT_BACKOFF_BASE", "1s"))
+	if err != nil {
+		log.Fatalf("Invalid LOCKOUT_BACKOFF_BASE value: %v", err)
+	}
+	lockoutBackoffMax, err := time.ParseDuration(getEnv("LOCKOUT_BACKOFF_MAX", "1m"))
+	if err != nil {
+		log.Fatalf("Invalid LOCKOUT_BACKOFF_MAX value: %v", err)
+	}
+	lockoutWindow, err := time.ParseDuration(getEnv("LOCKOUT_WINDOW", "1h"))
+	if err != nil {
+		log.Fatalf("Invalid LOCKOUT_WINDOW value: %v", err)
+	}
+	lockoutStore := strings.ToLower(getEnv("LOCKOUT_STORE", "postgres"))
+	if lockoutStore != "postgres" && lockoutStore != "memory" {
+		log.Fatalf("Invalid LOCKOUT_STORE value: %q", lockoutStore)
+	}
+
+	// Parse rate limits
+	rateLimitStore := strings.ToLower(getEnv("RATE_LIMIT_STORE", "memory"))
+	if rateLimitStore != "postgres" && rateLimitStore != "memory" {
+		log.Fatalf("Invalid RATE_LIMIT_STORE value: %q", rateLimitStore)
+	}
+	registerLimit := parseRateLimitRule("RATE_LIMIT_REGISTER", "5/1h", "ip")
+	resendVerificationLimit := parseRateLimitRule("RATE_LIMIT_RESEND_VERIFICATION", "3/1h", "email,ip")
+	passwordResetRequestLimit := parseRateLimitRule("RATE_LIMIT_PASSWORD_RESET_REQUEST", "3/1h", "email,ip")
+	magicLinkRequestLimit := parseRateLimitRule("RATE_LIMIT_MAGIC_LINK_REQUEST", "5/1h", "email,ip")
+
 	// Parse server port
 	serverPort, _ := strconv.Atoi(getEnv("SERVER_PORT", "8080"))
+	trustProxyHeaders, _ := strconv.ParseBool(getEnv("TRUST_PROXY_HEADERS", "false"))
 
 	baseURL := getEnv("BASE_URL", "http://localhost")
 	port := getEnv("SERVER_PORT", "8080")
@@ -132,8 +464,19 @@ func LoadConfig() (*Config, error) {
 			Name:     getEnv("DB_NAME", "affpilot_auth"),
 		},
 		JWT: JWTConfig{
-			Secret: getEnv("JWT_SECRET", "jwtsecretkey"),
-			Expiry: jwtExpiry,
+			Secret:              getEnv("JWT_SECRET", "jwtsecretkey"),
+			Algorithm:           jwtAlgorithm,
+			PrivateKeyPath:      getEnv("JWT_PRIVATE_KEY_PATH", ""),
+			KeyID:               getEnv("JWT_KEY_ID", ""),
+			KeyRotationInterval: keyRotationInterval,
+			KeyRetention:        keyRetention,
+			Expiry:              jwtExpiry,
+			RefreshExpiry:       refreshExpiry,
+			TokenLookup:         tokenLookup,
+			Issuer:              getEnv("JWT_ISSUER", url),
+			Audience:            getEnv("JWT_AUDIENCE", "affpilot-auth"),
+			AllowedAlgorithms:   allowedAlgorithms,
+			Leeway:              jwtLeeway,
 		},
 		Admin: AdminConfig{
 			Username: getEnv("SYSTEM_ADMIN_USERNAME", "admin"),
@@ -152,14 +495,164 @@ func LoadConfig() (*Config, error) {
 			VerificationTTL:  verificationTTL,
 		},
 		Server: ServerConfig{
-			Port: serverPort,
+			Port:              serverPort,
+			TrustProxyHeaders: trustProxyHeaders,
 		},
 		Password: PasswordConfig{
-			PasswordResetTTL: passwordTTL,
+			PasswordResetTTL:  passwordTTL,
+			HashAlgorithm:     hashAlgorithm,
+			Argon2Memory:      uint32(argon2Memory),
+			Argon2Iterations:  uint32(argon2Iterations),
+			Argon2Parallelism: uint8(argon2Parallelism),
+			BcryptCost:        bcryptCost,
+			MinLength:         passwordMinLength,
+			MaxLength:         passwordMaxLength,
+			RequiredClasses:   passwordClasses,
+			BannedListPath:    getEnv("PASSWORD_BANNED_LIST_PATH", ""),
+			CheckSimilarity:   passwordCheckSimilarity,
+			History:           passwordHistory,
+			BreachSource:      breachSource,
+			BreachPath:        breachPath,
+			BreachMinCount:    breachMinCount,
+		},
+		Cookie: CookieConfig{
+			Name:        getEnv("AUTH_COOKIE_NAME", "auth_token"),
+			RefreshName: getEnv("REFRESH_COOKIE_NAME", "refresh_token"),
+			Domain:      getEnv("COOKIE_DOMAIN", ""),
+			Secure:      cookieSecure,
+			SameSite:    cookieSameSite,
+		},
+		MFA: MFAConfig{
+			Issuer:     getEnv("MFA_ISSUER", "AffPilot Auth"),
+			PendingTTL: mfaPendingTTL,
+		},
+		MagicLink: MagicLinkConfig{
+			Enabled:         magicLinkEnabled,
+			TTL:             magicLinkTTL,
+			URL:             getEnv("MAGIC_LINK_URL", "http://localhost:5173/magic-link"),
+			MaxCodeAttempts: magicLinkMaxAttempts,
+		},
+		OAuth: OAuthConfig{
+			CodeTTL:        oauthCodeTTL,
+			AccessTokenTTL: oauthAccessTokenTTL,
+			IDTokenTTL:     oauthIDTokenTTL,
+		},
+		Tokens: AccessTokenConfig{
+			DefaultTTL: patDefaultTTL,
+			MaxTTL:     patMaxTTL,
+		},
+		WebAuthn: WebAuthnConfig{
+			RPID:    getEnv("WEBAUTHN_RP_ID", "localhost"),
+			RPName:  getEnv("WEBAUTHN_RP_NAME", "AffPilot Auth"),
+			Origins: webAuthnOrigins,
+			Timeout: webAuthnTimeout,
+		},
+		Lockout: LockoutConfig{
+			Store:         lockoutStore,
+			MaxAttempts:   lockoutMaxAttempts,
+			IPMaxAttempts: lockoutIPMaxAttempts,
+			Duration:      lockoutDuration,
+			BackoffBase:   lockoutBackoffBase,
+			BackoffMax:    lockoutBackoffMax,
+			Window:        lockoutWindow,
+		},
+		RateLimit: RateLimitConfig{
+			Store:                rateLimitStore,
+			Register:             registerLimit,
+			ResendVerification:   resendVerificationLimit,
+			PasswordResetRequest: passwordResetRequestLimit,
+			MagicLinkRequest:     magicLinkRequestLimit,
 		},
 	}, nil
 }
 
+// parseTokenLookup parses a comma separated list of token sources
+func parseTokenLookup(value string) ([]string, error) {
+	var sources []string
+	for _, source := range strings.Split(value, ",") {
+		source = strings.ToLower(strings.TrimSpace(source))
+		if source != "header" && source != "cookie" {
+			return nil, fmt.Errorf("unknown token source %q", source)
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// parseAlgorithms parses a comma separated list of JWT signing algorithms
+func parseAlgorithms(value string) ([]string, error) {
+	var algorithms []string
+	for _, alg := range strings.Split(value, ",") {
+		alg = strings.TrimSpace(alg)
+		switch alg {
+		case "HS256", "RS256", "ES256", "EdDSA":
+			algorithms = append(algorithms, alg)
+		default:
+			return nil, fmt.Errorf("unsupported algorithm %q", alg)
+		}
+	}
+	return algorithms, nil
+}
+
+// parsePasswordClasses parses a comma separated list of character classes a password must contain
+func parsePasswordClasses(value string) ([]string, error) {
+	var classes []string
+	for _, class := range strings.Split(value, ",") {
+		class = strings.ToLower(strings.TrimSpace(class))
+		if class == "" {
+			continue
+		}
+		if class != "lower" && class != "upper" && class != "digit" && class != "symbol" {
+			return nil, fmt.Errorf("unknown character class %q", class)
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// parseRateLimitRule reads a "requests/period" limit (e.g. "5/1h") from name and its keys from name_KEYS.
+// Invalid values stop the application like every other setting.
+func parseRateLimitRule(name, defaultLimit, defaultKeys string) RateLimitRule {
+	var rule RateLimitRule
+
+	value := getEnv(name, defaultLimit)
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		log.Fatalf("Invalid %s value: %q, expected requests/period", name, value)
+	}
+	var err error
+	if rule.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || rule.Requests < 0 {
+		log.Fatalf("Invalid %s value: %q", name, value)
+	}
+	if rule.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || rule.Period <= 0 {
+		log.Fatalf("Invalid %s value: %q", name, value)
+	}
+
+	for _, key := range strings.Split(getEnv(name+"_KEYS", defaultKeys), ",") {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key != "ip" && key != "email" && key != "user" {
+			log.Fatalf("Invalid %s_KEYS value: unknown key %q", name, key)
+		}
+		rule.Keys = append(rule.Keys, key)
+	}
+	return rule
+}
+
+// parseSameSite maps the COOKIE_SAMESITE setting to an http.SameSite value
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	case "default", "":
+		return http.SameSiteDefaultMode, nil
+	}
+	return 0, fmt.Errorf("unknown SameSite mode %q", value)
+}
+
 // Helper function to get environment variable with a default value
 func getEnv(key, defaultValue string) string {
 	value, exists := os.LookupEnv(key)
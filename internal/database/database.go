@@ -15,7 +15,7 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/google/uuid"
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
 
 	_ "github.com/lib/pq"
 )
@@ -143,7 +143,15 @@ func InitAdminUser(admin config.AdminConfig) {
 		return
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(admin.Password), bcrypt.DefaultCost)
+	// The admin password goes through the same policy as every other password
+	if violations := password.GetPolicy().Check(admin.Password, password.Subject{Username: admin.Username, Email: admin.Email}); len(violations) > 0 {
+		for _, v := range violations {
+			log.Println("SYSTEM_ADMIN_PASSWORD:", v.Message)
+		}
+		log.Fatalf("System admin password does not meet the password policy")
+	}
+
+	hashedPassword, err := password.Hash(admin.Password)
 	if err != nil {
 		log.Fatalf("Password hashing failed: %v", err)
 	}
@@ -154,12 +162,16 @@ func InitAdminUser(admin config.AdminConfig) {
 	INSERT INTO users (username, first_name, last_name, email, password_hash, user_type, email_verified)
 	VALUES ($1, 'System', 'Admin', $2, $3, $4, TRUE)
 	RETURNING id`,
-		admin.Username, admin.Email, string(hashedPassword), "system_admin").Scan(&userID)
+		admin.Username, admin.Email, hashedPassword, "system_admin").Scan(&userID)
 
 	if err != nil {
 		log.Fatalf("Failed to insert system admin user: %v", err)
 	}
 
+	if _, err = DB.Exec(`INSERT INTO password_history (user_id, password_hash, created_at) VALUES ($1, $2, NOW())`, userID, hashedPassword); err != nil {
+		log.Fatalf("Failed to record system admin password history: %v", err)
+	}
+
 	// Get role ID for 'system_admin'
 	var roleID uuid.UUID
 	err = DB.QueryRow(`SELECT id FROM roles WHERE name = 'system_admin'`).Scan(&roleID)
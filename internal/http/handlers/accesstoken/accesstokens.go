@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// CreateMyAccessToken creates a personal access token limited to some of the permissions of the authenticated user.
+// The token is only returned in this response.
+func CreateMyAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	// A limited token must not be able to mint a broader one
+	if _, limited := middleware.GetScope(r); limited {
+		utils.ErrorResponse(w, http.StatusForbidden, "Personal access tokens cannot be created with a limited token")
+		return
+	}
+
+	var req models.PersonalAccessTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+
+	// Validate required fields
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Name is required and must be at most 100 characters")
+		return
+	}
+	if len(req.Permissions) == 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "At least one permission is required")
+		return
+	}
+
+	// Step 1: Work out the lifetime
+	cfg := config.GetConfig()
+	ttl := cfg.Tokens.DefaultTTL
+	if req.ExpiresInDays != 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl <= 0 || ttl > cfg.Tokens.MaxTTL {
+		utils.ErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Tokens must expire within %d days", int(cfg.Tokens.MaxTTL.Hours()/24)))
+		return
+	}
+
+	// Step 2: The token can only carry permissions the user holds
+	available, err := middleware.FetchAllUserPermissions(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return
+	}
+	var permissions []string
+	seen := make(map[string]bool)
+	for _, perm := range req.Permissions {
+		// Entries are joined with spaces into the token scope, an empty or spaced one would widen or split it
+		perm = strings.TrimSpace(perm)
+		if perm == "" || strings.ContainsFunc(perm, unicode.IsSpace) {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Permissions must be non-empty names without spaces")
+			return
+		}
+		if seen[perm] {
+			continue
+		}
+		seen[perm] = true
+		if !middleware.CheckPermission([]string{perm}, available) {
+			utils.ErrorResponse(w, http.StatusForbidden, "You do not hold the permission: "+perm)
+			return
+		}
+		permissions = append(permissions, perm)
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 3: Store the token
+	token, err := services.CreatePersonalAccessToken(db, userID, req.Name, permissions, ttl)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create personal access token")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusCreated, "Personal access token created successfully, copy it now, it cannot be shown again", token)
+}
+
+// ListMyAccessTokens lists the personal access tokens of the authenticated user with their last use
+func ListMyAccessTokens(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	tokens, err := services.ListPersonalAccessTokens(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch personal access tokens")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Personal access tokens retrieved successfully", tokens)
+}
+
+// RevokeMyAccessToken revokes a personal access token of the authenticated user, it stops working right away
+func RevokeMyAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the token_id from the URL path
+	tokenID := mux.Vars(r)["token_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	revoked, err := services.RevokePersonalAccessToken(db, userID, tokenID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to revoke personal access token")
+		return
+	}
+	if !revoked {
+		utils.ErrorResponse(w, http.StatusNotFound, "Personal access token not found")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Personal access token revoked successfully", nil)
+}
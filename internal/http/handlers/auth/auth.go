@@ -10,10 +10,10 @@ import (
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
@@ -41,24 +41,39 @@ func sendVerificationEmail(email, token string) error {
 	return nil
 }
 
+// sendHTMLEmail sends an HTML email from the configured sender address.
+func sendHTMLEmail(toEmail, subject, body string) error {
+	cfg := config.GetConfig()
+
+	// Add HTML content-type in headers
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s",
+		cfg.Email.From, toEmail, subject, body)
+
+	auth := smtp.PlainAuth("", cfg.Email.Username, cfg.Email.Password, cfg.Email.Host)
+	return smtp.SendMail(
+		fmt.Sprintf("%s:%d", cfg.Email.Host, cfg.Email.Port),
+		auth,
+		cfg.Email.From,
+		[]string{toEmail},
+		[]byte(message),
+	)
+}
+
 // generateJWT generates a JWT token for the authenticated user.
-func generateJWT(userID, username, userType, secret string, expiry time.Duration) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id":   userID,
-		"username":  username,
-		"user_type": userType,
-		"exp":       time.Now().Add(expiry).Unix(),
-		"iat":       time.Now().Unix(),
-	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+// The sid claim ties the token to a row in the sessions table.
+func generateJWT(userID, username, userType, sessionID string, expiry time.Duration) (string, error) {
+	return tokens.Issue(tokens.PurposeAccess, tokens.Claims{
+		UserID:    userID,
+		Username:  username,
+		UserType:  userType,
+		SessionID: sessionID,
+	}, expiry)
 }
 
 //returns the global token
 
 func VerifyEmail(w http.ResponseWriter, r *http.Request) {
 
-	cfg := config.GetConfig()
 	vars := mux.Vars(r)
 	// token := strings.TrimSpace(vars["token"])
 	// log.Printf("Verification Token: %s", token)
@@ -67,18 +82,14 @@ func VerifyEmail(w http.ResponseWriter, r *http.Request) {
 
 	// Step 1: Verify token and get user ID
 	tokenString := strings.TrimSpace(vars["token"])
-	claims := &jwt.MapClaims{}
-
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWT.Secret), nil
-	})
-	if err != nil || !token.Valid {
+	claims, err := tokens.Parse(tokens.PurposeEmailVerification, tokenString)
+	if err != nil {
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid Token")
 		return
 	}
 
-	userID, ok := (*claims)["user_id"].(string)
-	if !ok {
+	userID := claims.UserID
+	if userID == "" {
 		// http.Error(w, "Invalid token payload", http.StatusBadRequest)
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid Token Payload")
 		return
@@ -3,14 +3,18 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type LoginRequest struct {
@@ -27,8 +31,17 @@ type UserInfo struct {
 
 // LoginResponse represents the JSON response for a successful login.
 type LoginResponse struct {
-	Token string   `json:"token"`
-	User  UserInfo `json:"user"`
+	Token              string   `json:"token"`
+	RefreshToken       string   `json:"refresh_token"`
+	User               UserInfo `json:"user"`
+	MustChangePassword bool     `json:"must_change_password,omitempty"` // only PUT /me/password is allowed until it is done
+}
+
+// MFAChallengeResponse is returned instead of a session when the password was correct but a second factor is required.
+type MFAChallengeResponse struct {
+	MFARequired bool     `json:"mfa_required"`
+	MFAToken    string   `json:"mfa_token"`
+	Methods     []string `json:"methods"`
 }
 
 func LoginUser(w http.ResponseWriter, r *http.Request) {
@@ -40,9 +53,8 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Trim spaces
+	// Trim spaces, the password is used as typed
 	req.Email = strings.TrimSpace(req.Email)
-	req.Password = strings.TrimSpace(req.Password)
 
 	// Validate required fields
 	if req.Email == "" || req.Password == "" {
@@ -51,8 +63,10 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Load the config
-	cfg:=config.GetConfig()
+	// Refuse to check the password while the account or the client IP is locked out
+	if !checkLoginThrottle(w, r, req.Email) {
+		return
+	}
 
 	// Connect to the database
 	db:=database.Connect()
@@ -64,6 +78,7 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 	err := db.QueryRow(query, req.Email).Scan(&userID, &username, &userType, &storedHash, &emailVerified)
 	if err == sql.ErrNoRows {
 		// http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		recordLoginFailure(r, req.Email)
 		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	} else if err != nil {
@@ -80,34 +95,162 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Compare passwords
-	if err := bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(req.Password)); err != nil {
+	verified := req.Password
+	match, rehash, err := password.Verify(verified, storedHash)
+	if !match && err == nil && strings.TrimSpace(req.Password) != req.Password {
+		// Accounts registered before passwords stopped being trimmed stored the trimmed password
+		verified = strings.TrimSpace(req.Password)
+		match, rehash, err = password.Verify(verified, storedHash)
+	}
+	if err != nil {
+		log.Println("Failed to verify password hash for user:", userID, "Error:", err)
+	}
+	if !match {
 		// http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		recordLoginFailure(r, req.Email)
 		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
+	// Upgrade hashes made with another algorithm or outdated cost parameters while the plain password is at hand
+	if rehash {
+		upgradePasswordHash(db, userID, storedHash, verified)
+	}
+
+	user := UserInfo{
+		ID:       userID,
+		Username: username,
+		Email:    req.Email,
+		Type:     userType,
+	}
+
+	// Users with a second factor get a short-lived token to finish the login
+	// at /auth/mfa/verify or /auth/webauthn/login/finish
+	methods, err := services.MFAMethods(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check two-factor authentication")
+		return
+	}
+	if len(methods) > 0 {
+		sendMFAChallenge(w, user, methods)
+		return
+	}
+
+	completeLogin(w, r, db, user)
+}
+
+// upgradePasswordHash replaces a stored hash with one made by the current hasher.
+// A failure only delays the upgrade to the next login.
+func upgradePasswordHash(db *sql.DB, userID, oldHash, plain string) {
+	newHash, err := password.Hash(plain)
+	if err != nil {
+		log.Println("Failed to rehash password for user:", userID, "Error:", err)
+		return
+	}
+	// Only replace the hash that was verified, a concurrent password change wins
+	_, err = db.Exec(`UPDATE users SET password_hash = $1 WHERE id = $2 AND password_hash = $3`, newHash, userID, oldHash)
+	if err != nil {
+		log.Println("Failed to upgrade password hash for user:", userID, "Error:", err)
+	}
+}
+
+// sendMFAChallenge answers the password step of a login that still needs a second factor.
+func sendMFAChallenge(w http.ResponseWriter, user UserInfo, methods []string) {
+	cfg := config.GetConfig()
+
+	mfaToken, err := tokens.Issue(tokens.PurposeMFAPending, tokens.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+	}, cfg.MFA.PendingTTL)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Two-factor authentication required", MFAChallengeResponse{
+		MFARequired: true,
+		MFAToken:    mfaToken,
+		Methods:     methods,
+	})
+}
+
+// completeLogin creates the session, the access token and the refresh token of an authenticated user
+// and writes them to the response. Every login method ends here.
+func completeLogin(w http.ResponseWriter, r *http.Request, db *sql.DB, user UserInfo) {
+	cfg := config.GetConfig()
+
+	// Create a server-side session for this login
+	sessionID, err := services.CreateSession(db, user.ID, r.UserAgent(), utils.ClientIP(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create session")
+		return
+	}
+
 	// Generate JWT token
-	token, err := generateJWT(userID, username, userType, cfg.JWT.Secret, cfg.JWT.Expiry)
+	token, err := generateJWT(user.ID, user.Username, user.Type, sessionID, cfg.JWT.Expiry)
 	if err != nil {
 		// http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
 		return
 	}
-	expiresAt := time.Now().Add(24 * time.Hour)
-	cookie := &http.Cookie{
-		Name:     "auth_token",
-		Value:    token,
+
+	// Generate refresh token, every login starts a new token family
+	refreshToken, _, err := issueRefreshToken(db, user.ID, sessionID, "")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate refresh token")
+		return
+	}
+
+	// The login is complete, forget earlier failures of the account
+	recordLoginSuccess(user.Email)
+
+	mustChangePassword, err := services.MustChangePassword(db, user.ID)
+	if err != nil {
+		log.Println("Failed to check forced password change for user:", user.ID, "Error:", err)
+	}
+
+	// Set cookies in response
+	setAuthCookies(w, token, refreshToken)
+
+	sendLoginResponse(w, http.StatusAccepted, "Login successful", LoginResponse{
+		Token:              token,
+		RefreshToken:       refreshToken,
+		User:               user,
+		MustChangePassword: mustChangePassword,
+	})
+}
+
+// setAuthCookies stores the access token and the refresh token in HTTP-only cookies.
+func setAuthCookies(w http.ResponseWriter, token, refreshToken string) {
+	cfg := config.GetConfig()
+
+	http.SetCookie(w, newAuthCookie(cfg.Cookie.Name, token, "/", time.Now().Add(cfg.JWT.Expiry)))
+
+	// The refresh token is only ever needed by the auth endpoints
+	http.SetCookie(w, newAuthCookie(cfg.Cookie.RefreshName, refreshToken, refreshCookiePath, time.Now().Add(cfg.JWT.RefreshExpiry)))
+}
+
+// newAuthCookie builds an HTTP-only cookie using the configured domain, Secure and SameSite settings.
+func newAuthCookie(name, value, path string, expires time.Time) *http.Cookie {
+	cfg := config.GetConfig()
+
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cfg.Cookie.Domain,
+		Expires:  expires,
 		HttpOnly: true,
-		Expires:  expiresAt,
-		Secure:   false, // Set to true in production with HTTPS
-		Path:     "/",
+		Secure:   cfg.Cookie.Secure,
+		SameSite: cfg.Cookie.SameSite,
 	}
-	// Set cookie in response
-	http.SetCookie(w, cookie)
-	
+}
+
+// sendLoginResponse writes the token pair and the user info as JSON.
+func sendLoginResponse(w http.ResponseWriter, statusCode int, message string, data LoginResponse) {
 	// Set header and status code
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(statusCode)
 
 	// Prepare your JSON response manually
 	response := struct {
@@ -115,19 +258,11 @@ func LoginUser(w http.ResponseWriter, r *http.Request) {
 		Message string        `json:"message"`
 		Data    LoginResponse `json:"data"`
 	}{
-		Status:  "202",
-		Message: "Login successful",
-		Data: LoginResponse{
-			Token: token,
-			User: UserInfo{
-				ID:       userID,
-				Username: username,
-				Email:    req.Email,
-				Type:     userType,
-			},
-		},
+		Status:  strconv.Itoa(statusCode),
+		Message: message,
+		Data:    data,
 	}
 
 	// Encode response to JSON and write it to response body
 	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
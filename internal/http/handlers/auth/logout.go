@@ -1,25 +1,46 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 
 	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
-// LogoutUser handles the user logout process by clearing the auth cookie.
+// LogoutUser handles the user logout process by clearing the auth cookies
+// and revoking the refresh token family of the current login.
 func LogoutUser(w http.ResponseWriter, r *http.Request) {
-	// Clear the auth_token cookie
-	cookie := &http.Cookie{
-		Name:     "auth_token",
-		Value:    "", 
-		Path:     "/",
-		Expires:  time.Now().Add(-1 * time.Hour), 
-		HttpOnly: true,
-		Secure:   false,
+	cfg := config.GetConfig()
+	db := database.Connect()
+
+	// Revoke the session of the access token, this also covers bearer clients without cookies
+	if tokenString, err := middleware.ExtractToken(r); err == nil {
+		claims, err := tokens.Parse(tokens.PurposeAccess, tokenString)
+		if err == nil && claims.SessionID != "" {
+			if _, err := services.RevokeSession(db, claims.UserID, claims.SessionID); err != nil {
+				log.Println("Failed to revoke session on logout:", err)
+			}
+		}
+	}
+
+	// Revoke the refresh token if the client sent one
+	if refreshToken, err := refreshTokenFromRequest(r); err == nil && refreshToken != "" {
+		if err := revokeRefreshTokenFamily(db, refreshToken); err != nil {
+			log.Println("Failed to revoke refresh token on logout:", err)
+		}
 	}
 
-	http.SetCookie(w, cookie)
+	// Clear the auth and refresh cookies
+	expired := time.Now().Add(-1 * time.Hour)
+	http.SetCookie(w, newAuthCookie(cfg.Cookie.Name, "", "/", expired))
+	http.SetCookie(w, newAuthCookie(cfg.Cookie.RefreshName, "", refreshCookiePath, expired))
 
 	// json.NewEncoder(w).Encode(response)
 	utils.SuccessResponse(w, http.StatusOK, "Logout successful", nil)
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// magicLinkRequestedMessage is answered whether or not the email belongs to an account
+const magicLinkRequestedMessage = "If a verified account uses this email, a sign-in link has been sent"
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
+
+// MagicLinkConsumeRequest carries either the token from the link or the email address and the 6-digit code
+type MagicLinkConsumeRequest struct {
+	Token string `json:"token"`
+	Email string `json:"email"`
+	Code  string `json:"code"`
+}
+
+// RequestMagicLink emails a single-use sign-in link and code to a verified account
+func RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Email is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	var userID string
+	err := db.QueryRow("SELECT id FROM users WHERE email = $1 AND email_verified = true", req.Email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		log.Println("Magic link requested for unknown or unverified email:", req.Email)
+		utils.SuccessResponse(w, http.StatusOK, magicLinkRequestedMessage, nil)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	// Issuing a link revokes the ones sent earlier
+	token, code, err := services.IssueMagicLink(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create magic link")
+		log.Println("Failed to create magic link for:", req.Email, "Error:", err)
+		return
+	}
+
+	// Send in the background so known emails do not answer measurably slower
+	go func() {
+		if err := sendMagicLinkEmail(req.Email, token, code); err != nil {
+			log.Println("Failed to send magic link email to:", req.Email, "Error:", err)
+		}
+	}()
+
+	utils.SuccessResponse(w, http.StatusOK, magicLinkRequestedMessage, nil)
+}
+
+// ConsumeMagicLink trades a magic link token, or an email address and code, for a session.
+// Accounts with a second factor still have to pass it, exactly like after a password.
+func ConsumeMagicLink(w http.ResponseWriter, r *http.Request) {
+	var req MagicLinkConsumeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	req.Code = strings.TrimSpace(req.Code)
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Use up the token or the code
+	var userID string
+	var err error
+	switch {
+	case req.Token != "":
+		userID, err = services.ConsumeMagicLinkToken(db, req.Token)
+	case req.Email != "" && req.Code != "":
+		// Codes are short, guesses count towards the login lockout
+		if !checkLoginThrottle(w, r, req.Email) {
+			return
+		}
+		err = db.QueryRow("SELECT id FROM users WHERE email = $1", req.Email).Scan(&userID)
+		if err == sql.ErrNoRows {
+			err = services.ErrInvalidMagicLink
+		} else if err == nil {
+			err = services.ConsumeMagicLinkCode(db, userID, req.Code)
+		}
+		if err == services.ErrInvalidMagicLink {
+			recordLoginFailure(r, req.Email)
+		}
+	default:
+		utils.ErrorResponse(w, http.StatusBadRequest, "Token, or email and code, are required")
+		return
+	}
+	if err == services.ErrInvalidMagicLink {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired magic link")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check magic link")
+		log.Println("Failed to consume magic link:", err)
+		return
+	}
+
+	// Step 2: Load the user
+	var user UserInfo
+	var emailVerified bool
+	err = db.QueryRow("SELECT id, username, email, user_type, email_verified FROM users WHERE id = $1", userID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Type, &emailVerified)
+	if err == sql.ErrNoRows || (err == nil && !emailVerified) {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired magic link")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	// Step 3: The link replaces the password, not the second factor
+	methods, err := services.MFAMethods(db, user.ID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check two-factor authentication")
+		return
+	}
+	if len(methods) > 0 {
+		sendMFAChallenge(w, user, methods)
+		return
+	}
+
+	completeLogin(w, r, db, user)
+}
+
+// sendMagicLinkEmail sends the sign-in link and the code that can be typed instead
+func sendMagicLinkEmail(toEmail, token, code string) error {
+	cfg := config.GetConfig()
+
+	link := fmt.Sprintf("%s?token=%s", cfg.MagicLink.URL, url.QueryEscape(token))
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+			<h2>Sign in</h2>
+			<p>Hello,</p>
+			<p>Click the button below to sign in:</p>
+			<a href="%s" style="display: inline-block; padding: 10px 20px; background-color: #007BFF; color: white; text-decoration: none; border-radius: 4px;">Sign in</a>
+			<p>Or enter this code:</p>
+			<h3 style="background-color: #f3f3f3; padding: 10px; display: inline-block;">%s</h3>
+			<p>The link and the code work once and expire in %d minutes.</p>
+			<p>If you did not try to sign in, please ignore this email.</p>
+			<p>Thank you.</p>
+		</body>
+		</html>
+	`, link, code, int(math.Ceil(cfg.MagicLink.TTL.Minutes())))
+
+	return sendHTMLEmail(toEmail, "Your sign-in link", body)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+// VerifyMFALogin finishes a login that needs a second factor.
+// It trades the mfa_token from /auth/login and a TOTP or recovery code for a full session.
+func VerifyMFALogin(w http.ResponseWriter, r *http.Request) {
+	var req MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if req.MFAToken == "" || req.Code == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "MFA token and code are required")
+		return
+	}
+
+	// Step 1: The password step must have succeeded recently
+	claims, err := tokens.Parse(tokens.PurposeMFAPending, req.MFAToken)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 2: Load the user
+	var user UserInfo
+	err = db.QueryRow("SELECT id, username, email, user_type FROM users WHERE id = $1", claims.UserID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Type)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	// Step 3: Check the second factor, failures count towards the lockout like wrong passwords
+	if !checkLoginThrottle(w, r, user.Email) {
+		return
+	}
+	valid, err := services.VerifyMFACode(db, user.ID, req.Code)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to verify code")
+		return
+	}
+	if !valid {
+		log.Println("Invalid MFA code for user:", user.ID)
+		recordLoginFailure(r, user.Email)
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid two-factor authentication code")
+		return
+	}
+
+	// Step 4: Create the session
+	completeLogin(w, r, db, user)
+}
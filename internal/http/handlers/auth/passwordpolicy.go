@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// checkPasswordPolicy answers with the list of broken rules when a new password is rejected.
+// It returns true when the password may be set.
+func checkPasswordPolicy(w http.ResponseWriter, db *sql.DB, userID, pw string, subject password.Subject) bool {
+	violations, err := services.CheckNewPassword(db, userID, pw, subject)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check password policy")
+		log.Println("Failed to check password policy for user:", userID, "Error:", err)
+		return false
+	}
+	if len(violations) > 0 {
+		utils.ErrorResponseWithData(w, http.StatusBadRequest, "Password does not meet the password policy", map[string]interface{}{
+			"violations": violations,
+		})
+		return false
+	}
+	return true
+}
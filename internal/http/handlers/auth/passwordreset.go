@@ -1,31 +1,20 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
 	"log"
 	"net/http"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/sagorsarker04/Developer-Assignment/internal/config"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
-	"golang.org/x/crypto/bcrypt"
 )
 
-//var jwtSecret = []byte("your_secret_key") // Replace with your actual secret
-
-type Claims struct {
-	Email   string `json:"email"`
-	Purpose string `json:"purpose"`
-	jwt.RegisteredClaims
-}
-
+// PasswordReset sets a new password with the token from the reset link
 func PasswordReset(w http.ResponseWriter, r *http.Request) {
 
-	cfg:=config.GetConfig()
-	jwtSecret:=cfg.JWT.Secret
-
 	type RequestBody struct {
 		Token       string `json:"token"`
 		NewPassword string `json:"new_password"`
@@ -38,53 +27,26 @@ func PasswordReset(w http.ResponseWriter, r *http.Request) {
 		log.Println("Invalid request body:", err)
 		return
 	}
-	if len(reqBody.NewPassword) < 8 {
-	utils.ErrorResponse(w, http.StatusBadRequest, "Password must be at least 8 characters")
-	return
-}
 
-	// Parse and validate JWT token
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(reqBody.Token, claims, func(token *jwt.Token) (interface{}, error) {
-    return []byte(jwtSecret), nil 
-})
-	if err != nil || !token.Valid {
+	// Check the reset token, it is only used up once the password is changed
+	db := database.Connect()
+	user, err := services.LookupPasswordResetToken(db, reqBody.Token)
+	if err == services.ErrInvalidResetToken {
 		// http.Error(w, "Invalid or expired reset token", http.StatusBadRequest)
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid or expired reset token")
-		log.Println("Invalid or expired token:", err)
 		return
-	}
-
-	if claims.Purpose != "password_reset" {
-		// http.Error(w, "Invalid token purpose", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid token purpose")
-		log.Println("Invalid token purpose for email:", claims.Email)
-		return
-	}
-
-	if time.Now().After(claims.ExpiresAt.Time) {
-		// http.Error(w, "Token has expired", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "Token has expired")
-		log.Println("Token expired for email:", claims.Email)
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check reset token")
+		log.Println("Failed to check reset token:", err)
 		return
 	}
 
-	// Check if the user exists and email is verified
-	db := database.Connect()
-	var emailVerified bool
-	err = db.QueryRow(
-		"SELECT email_verified FROM users WHERE email = $1",
-		claims.Email,
-	).Scan(&emailVerified)
-	if err != nil || !emailVerified {
-		// http.Error(w, "User not found or email not verified", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "User not found or email not verified")
-		log.Println("User not found or email not verified for:", claims.Email)
+	if !checkPasswordPolicy(w, db, user.ID, reqBody.NewPassword, password.Subject{Username: user.Username, Email: user.Email}) {
 		return
 	}
 
 	// Hash the new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reqBody.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := password.Hash(reqBody.NewPassword)
 	if err != nil {
 		// http.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to hash password")
@@ -92,19 +54,38 @@ func PasswordReset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update the password
-	_, err = db.Exec(
-		"UPDATE users SET password_hash = $1, updated_at = NOW() WHERE email = $2",
-		string(hashedPassword),
-		claims.Email,
-	)
-	if err != nil {
-		// http.Error(w, "Failed to update password", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update password")
-		log.Println("Failed to update password for:", claims.Email, "Error:", err)
+	// Update the password and use up the token
+	if !resetPassword(w, db, reqBody.Token, user, hashedPassword) {
 		return
 	}
 
 	utils.SuccessResponse(w, http.StatusOK, "Password reset successfully", nil)
-	log.Println("Password reset successfully for:", claims.Email)
+	log.Println("Password reset successfully for:", user.Email)
+}
+
+// resetPassword stores the new password hash while using up the reset token, then logs the user out everywhere,
+// a reset is how accounts are recovered after a compromise.
+// It answers the request and returns false when the token was used in the meantime or the update failed.
+func resetPassword(w http.ResponseWriter, db *sql.DB, token string, user *services.PasswordResetUser, hashedPassword string) bool {
+	err := services.ResetPassword(db, token, user.ID, hashedPassword)
+	if err == services.ErrInvalidResetToken {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid or expired reset token")
+		return false
+	} else if err != nil {
+		// http.Error(w, "Failed to update password", http.StatusInternalServerError)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update password")
+		log.Println("Failed to update password for:", user.Email, "Error:", err)
+		return false
+	}
+
+	if err := services.RecordPasswordHistory(db, user.ID, hashedPassword); err != nil {
+		log.Println("Failed to record password history for user:", user.ID, "Error:", err)
+	}
+
+	if _, err := services.RevokeAllSessions(db, user.ID, ""); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Password reset but failed to revoke sessions")
+		log.Println("Failed to revoke sessions after password reset for:", user.Email, "Error:", err)
+		return false
+	}
+	return true
 }
@@ -4,12 +4,15 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
-	"golang.org/x/crypto/bcrypt"
 )
 
+// PasswordResetConfirm sets a new password with the email address and the code from the reset email
 func PasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
 
 	type RequestBody struct {
@@ -28,28 +31,28 @@ func PasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
 
 	db := database.Connect()
 
-	// Check if the reset token matches for this user and email is verified
-	var storedToken string
-	err := db.QueryRow(
-		"SELECT reset_token FROM users WHERE email = $1 AND email_verified = true",
-		reqBody.Email,
-	).Scan(&storedToken)
-	if err != nil {
-		// http.Error(w, "User not found or email not verified", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "User not found or email not verified")
-		log.Println("User not found or email not verified for:", reqBody.Email)
-		return
+	// Check the reset token belongs to this email, it is only used up once the password is changed
+	user, err := services.LookupPasswordResetToken(db, reqBody.ResetToken)
+	if err == nil && !strings.EqualFold(user.Email, strings.TrimSpace(reqBody.Email)) {
+		err = services.ErrInvalidResetToken
 	}
-
-	if storedToken == "" || storedToken != reqBody.ResetToken {
+	if err == services.ErrInvalidResetToken {
 		// http.Error(w, "Invalid or expired reset token", http.StatusBadRequest)
 		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid or expired reset token")
 		log.Println("Invalid reset token for:", reqBody.Email)
 		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check reset token")
+		log.Println("Failed to check reset token:", err)
+		return
+	}
+
+	if !checkPasswordPolicy(w, db, user.ID, reqBody.NewPassword, password.Subject{Username: user.Username, Email: user.Email}) {
+		return
 	}
 
 	// Hash the new password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(reqBody.NewPassword), bcrypt.DefaultCost)
+	hashedPassword, err := password.Hash(reqBody.NewPassword)
 	if err != nil {
 		// http.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to hash password")
@@ -57,16 +60,8 @@ func PasswordResetConfirm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update the password and clear the reset token
-	_, err = db.Exec(
-		"UPDATE users SET password_hash = $1, reset_token = NULL, updated_at = NOW() WHERE email = $2",
-		string(hashedPassword),
-		reqBody.Email,
-	)
-	if err != nil {
-		// http.Error(w, "Failed to update password", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update password")
-		log.Println("Failed to update password for:", reqBody.Email, "Error:", err)
+	// Update the password and use up the token
+	if !resetPassword(w, db, reqBody.ResetToken, user, hashedPassword) {
 		return
 	}
 
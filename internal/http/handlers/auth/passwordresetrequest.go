@@ -5,23 +5,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
-	"net/smtp"
-	"time"
+	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 )
 
-type ResetPasswordClaims struct {
-	Email   string `json:"email"`
-	Purpose string `json:"purpose"`
-	jwt.RegisteredClaims
-}
+// passwordResetRequestedMessage is answered whether or not the email belongs to an account,
+// so the endpoint cannot be used to find out which emails are registered.
+const passwordResetRequestedMessage = "If a verified account uses this email, a password reset email has been sent"
 
 // PasswordResetRequest handles requests to initiate password reset by sending an email with a reset token.
 func PasswordResetRequest(w http.ResponseWriter, r *http.Request) {
@@ -36,92 +33,52 @@ func PasswordResetRequest(w http.ResponseWriter, r *http.Request) {
 		log.Println("Invalid request body:", err)
 		return
 	}
-	//frontend er jonno token
-	cfg := config.GetConfig()
-	secretKey := cfg.JWT.Secret
-
-	claims := ResetPasswordClaims{
-		Email:   reqBody.Email,
-		Purpose: "password_reset",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	email := strings.TrimSpace(reqBody.Email)
+	if email == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Email is required")
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	db := database.Connect()
 
-	signedToken, err := token.SignedString([]byte(secretKey))
-	if err != nil {
-		// http.Error(w, "Failed to generate token", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
-		log.Println("JWT generation error:", err)
+	// Only verified accounts can reset their password
+	var userID string
+	err := db.QueryRow("SELECT id FROM users WHERE email = $1 AND email_verified = true", email).Scan(&userID)
+	if err == sql.ErrNoRows {
+		log.Println("Password reset requested for unknown or unverified email:", email)
+		utils.SuccessResponse(w, http.StatusOK, passwordResetRequestedMessage, nil)
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
 		return
 	}
 
-	fmt.Println(signedToken)
-	//front er toekn upore
-
-	db := database.Connect()
-
-	//Generate a new reset token
-	resetToken := uuid.NewString()
-	email := reqBody.Email
-	query := `Select id from users where email=$1`
-	//Store the reset token in the database
-	res, err := db.Exec(
-		"UPDATE users SET reset_token = $1, updated_at = NOW() WHERE email = $2 AND email_verified = true",
-		resetToken,
-		reqBody.Email,
-	)
+	// Issuing a token revokes the ones sent earlier
+	resetToken, err := services.IssuePasswordResetToken(db, userID)
 	if err != nil {
 		// http.Error(w, "Failed to store reset token", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to store reset token")
-		log.Println("Failed to store reset token for:", reqBody.Email, "Error:", err)
+		log.Println("Failed to store reset token for:", email, "Error:", err)
 		return
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil || rowsAffected == 0 {
-		// http.Error(w, "No verified user found with this email", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "No verified user found with this email")
-		log.Println("No verified user found with email:", reqBody.Email)
-		return
-	}
-	var userID int
-	err = db.QueryRow(query, email).Scan(&userID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			fmt.Println("No user found with this email")
-		} else {
-			fmt.Println("Invalid query")
+	// Send in the background, waiting for SMTP would make known emails answer measurably slower
+	go func() {
+		if err := sendResetToken(email, resetToken); err != nil {
+			log.Println("Failed to send password reset email to:", email, "Error:", err)
 		}
-	}
-	fmt.Println("User id is", userID)
-
-	if err := sendResetToken(reqBody.Email, resetToken, signedToken); err != nil {
-		// http.Error(w, "Failed to send password reset email", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to send password reset email")
-		log.Println("Failed to send password reset email to:", reqBody.Email, "Error:", err)
-		return
-	}
-
-	utils.SuccessResponse(w, http.StatusOK, "Password reset email sent successfully", nil)
+	}()
 
+	utils.SuccessResponse(w, http.StatusOK, passwordResetRequestedMessage, nil)
 }
 
 // sendResetToken sends a password reset email to the specified email address.
-func sendResetToken(toEmail, resetToken, signedToken string) error {
+func sendResetToken(toEmail, resetToken string) error {
 	cfg := config.GetConfig()
 
-	from := cfg.Email.From
-	password := cfg.Email.Password
-	smtpHost := cfg.Email.Host
-	smtpPort := cfg.Email.Port
-
 	subject := "Password Reset Request"
 
-	resetLink := fmt.Sprintf("http://localhost:5173/reset-password?token=%s", signedToken)
+	resetLink := fmt.Sprintf("http://localhost:5173/reset-password?token=%s", resetToken)
 
 	// Use HTML content for email body
 	body := fmt.Sprintf(`
@@ -133,26 +90,14 @@ func sendResetToken(toEmail, resetToken, signedToken string) error {
 			<h3 style="background-color: #f3f3f3; padding: 10px; display: inline-block;">%s</h3>
 			<p>Or click the button below to reset your password directly:</p>
 			<a href="%s" style="display: inline-block; padding: 10px 20px; background-color: #007BFF; color: white; text-decoration: none; border-radius: 4px;">Reset Password</a>
+			<p>The code works once and expires in %d minutes.</p>
 			<p>If you did not request a password reset, please ignore this email.</p>
 			<p>Thank you.</p>
 		</body>
 		</html>
-	`, resetToken, resetLink)
-
-	// Add HTML content-type in headers
-	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s",
-		from, toEmail, subject, body)
+	`, resetToken, resetLink, int(math.Ceil(cfg.Password.PasswordResetTTL.Minutes())))
 
-	auth := smtp.PlainAuth("", from, password, smtpHost)
-
-	err := smtp.SendMail(
-		fmt.Sprintf("%s:%d", smtpHost, smtpPort),
-		auth,
-		from,
-		[]string{toEmail},
-		[]byte(message),
-	)
-	if err != nil {
+	if err := sendHTMLEmail(toEmail, subject, body); err != nil {
 		log.Println("Failed to send password reset email to:", toEmail, "Error:", err)
 		return err
 	}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// refreshCookiePath limits the refresh token cookie to the auth endpoints
+const refreshCookiePath = "/api/v1/auth"
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// queryRower is satisfied by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// issueRefreshToken stores a new refresh token for the user's session and returns the raw token and its row ID.
+// An empty familyID starts a new token family, which happens on every fresh login.
+func issueRefreshToken(q queryRower, userID, sessionID, familyID string) (string, string, error) {
+	cfg := config.GetConfig()
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	if familyID == "" {
+		familyID = uuid.NewString()
+	}
+
+	var tokenID string
+	err = q.QueryRow(`
+		INSERT INTO refresh_tokens (user_id, session_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5), NOW())
+		RETURNING id`,
+		userID, sessionID, familyID, utils.HashToken(token), cfg.JWT.RefreshExpiry.Seconds(),
+	).Scan(&tokenID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, tokenID, nil
+}
+
+// revokeRefreshTokenFamily revokes every token that belongs to the same family as the given raw token,
+// and the session the family was issued for.
+func revokeRefreshTokenFamily(db *sql.DB, token string) error {
+	var userID, familyID string
+	var sessionID sql.NullString
+	err := db.QueryRow(`SELECT user_id, family_id, session_id FROM refresh_tokens WHERE token_hash = $1`, utils.HashToken(token)).
+		Scan(&userID, &familyID, &sessionID)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return err
+	}
+
+	if sessionID.Valid {
+		_, err = services.RevokeSession(db, userID, sessionID.String)
+	}
+	return err
+}
+
+// refreshTokenFromRequest reads the refresh token from the JSON body, falling back to the cookie.
+func refreshTokenFromRequest(r *http.Request) (string, error) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		return "", err
+	}
+	if req.RefreshToken == "" {
+		if cookie, err := r.Cookie(config.GetConfig().Cookie.RefreshName); err == nil {
+			req.RefreshToken = cookie.Value
+		}
+	}
+	return req.RefreshToken, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and a new refresh token.
+// Every refresh token can be used once. Presenting an already used token revokes its whole family.
+func RefreshToken(w http.ResponseWriter, r *http.Request) {
+	refreshToken, err := refreshTokenFromRequest(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if refreshToken == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Refresh token is required")
+		return
+	}
+
+	cfg := config.GetConfig()
+	db := database.Connect()
+
+	tx, err := db.Begin()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to start transaction")
+		return
+	}
+	defer tx.Rollback()
+
+	// Step 1: Lock the presented token
+	var tokenID, userID, familyID string
+	var sessionID sql.NullString
+	var expired, used, revoked bool
+	err = tx.QueryRow(`
+		SELECT id, user_id, session_id, family_id, expires_at <= NOW(), used_at IS NOT NULL, revoked_at IS NOT NULL
+		FROM refresh_tokens
+		WHERE token_hash = $1
+		FOR UPDATE`,
+		utils.HashToken(refreshToken),
+	).Scan(&tokenID, &userID, &sessionID, &familyID, &expired, &used, &revoked)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch refresh token")
+		return
+	}
+
+	// Step 2: Reuse detection, a token that was already rotated or revoked kills the whole family
+	if used || revoked {
+		_, err = tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+		if err == nil {
+			_, err = tx.Exec(`UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, sessionID)
+		}
+		if err != nil || tx.Commit() != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to revoke refresh tokens")
+			return
+		}
+		log.Println("Refresh token reuse detected for user:", userID, "family:", familyID)
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Refresh token has already been used")
+		return
+	}
+
+	if expired {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Refresh token has expired")
+		return
+	}
+
+	// Step 2.1: The session must still be alive, revoking a session revokes its refresh tokens too
+	if !sessionID.Valid {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	}
+	active, err := services.IsSessionActive(db, sessionID.String, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check session")
+		return
+	} else if !active {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Session has been revoked")
+		return
+	}
+
+	// Step 3: Load the user the token belongs to
+	var user UserInfo
+	err = tx.QueryRow("SELECT id, username, email, user_type FROM users WHERE id = $1", userID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Type)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	// Step 4: Rotate, the new token stays in the same family
+	newRefreshToken, newTokenID, err := issueRefreshToken(tx, userID, sessionID.String, familyID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate refresh token")
+		return
+	}
+
+	_, err = tx.Exec(`UPDATE refresh_tokens SET used_at = NOW(), replaced_by = $1 WHERE id = $2`, newTokenID, tokenID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to rotate refresh token")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to rotate refresh token")
+		return
+	}
+
+	if err := services.ExtendSession(db, sessionID.String); err != nil {
+		log.Println("Failed to extend session:", sessionID.String, "Error:", err)
+	}
+
+	// Step 5: Issue a new access token
+	token, err := generateJWT(user.ID, user.Username, user.Type, sessionID.String, cfg.JWT.Expiry)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate token")
+		return
+	}
+
+	mustChangePassword, err := services.MustChangePassword(db, user.ID)
+	if err != nil {
+		log.Println("Failed to check forced password change for user:", user.ID, "Error:", err)
+	}
+
+	setAuthCookies(w, token, newRefreshToken)
+	sendLoginResponse(w, http.StatusOK, "Token refreshed successfully", LoginResponse{
+		Token:              token,
+		RefreshToken:       newRefreshToken,
+		User:               user,
+		MustChangePassword: mustChangePassword,
+	})
+}
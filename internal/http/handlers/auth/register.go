@@ -3,17 +3,19 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"log"
 	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type RegisterRequest struct {
@@ -43,7 +45,6 @@ func RegisterUser(w http.ResponseWriter, r *http.Request) {
 	req.Email = strings.TrimSpace(req.Email)
 	req.FirstName = strings.TrimSpace(req.FirstName)
 	req.LastName = strings.TrimSpace(req.LastName)
-	req.Password = strings.TrimSpace(req.Password)
 
 	// Validate required fields
 	if req.Username == "" || req.Email == "" || req.Password == "" {
@@ -52,14 +53,15 @@ func RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if len(req.Password) < 8 || len(req.Password) > 20 {
-		// http.Error(w, "Password should be 8 to 20 characters long", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "Password should be 8 to 20 characters long")
+	db := database.Connect()
+
+	// The password is checked as typed, it is not trimmed
+	if !checkPasswordPolicy(w, db, "", req.Password, password.Subject{Username: req.Username, Email: req.Email}) {
 		return
 	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := password.Hash(req.Password)
 	if err != nil {
 		// http.Error(w, "Failed to hash password", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to hash password")
@@ -76,7 +78,7 @@ func RegisterUser(w http.ResponseWriter, r *http.Request) {
 		FirstName:     req.FirstName,
 		LastName:      req.LastName,
 		Email:         req.Email,
-		PasswordHash:  string(hashedPassword),
+		PasswordHash:  hashedPassword,
 		EmailVerified: false,
 		UserType:      "user",
 		Active:        true,
@@ -86,8 +88,6 @@ func RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Insert the user into the database
-	db := database.Connect()
-
 	// Check if the email already exists
 	if exists, err := isEmailExists(db, user.Email); err != nil {
 		// http.Error(w, "Failed to check email existence", http.StatusInternalServerError)
@@ -123,19 +123,16 @@ func RegisterUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cfg := config.GetConfig()
-	secretKey := cfg.JWT.Secret
-	// Create verification token
-	claims := jwt.MapClaims{
-		"user_id": user.ID,
-		"email":   user.Email,
-		"exp":     time.Now().Add(cfg.Email.VerificationTTL).Unix(),
-		"iat":     time.Now().Unix(),
-		"purpose": "email_verification",
+	if err := services.RecordPasswordHistory(db, user.ID, user.PasswordHash); err != nil {
+		log.Println("Failed to record password history for user:", user.ID, "Error:", err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	verificationToken, err := token.SignedString([]byte(secretKey))
+	cfg := config.GetConfig()
+	// Create verification token
+	verificationToken, err := tokens.Issue(tokens.PurposeEmailVerification, tokens.Claims{
+		UserID: user.ID,
+		Email:  user.Email,
+	}, cfg.Email.VerificationTTL)
 	if err != nil {
 		// http.Error(w, "Failed to generate verification token", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate verification token")
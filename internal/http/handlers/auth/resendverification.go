@@ -5,12 +5,11 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/sagorsarker04/Developer-Assignment/internal/config"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
@@ -67,17 +66,10 @@ func ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
 
 	// Step 2: Generate a new verification token
 	cfg := config.GetConfig()
-	secretKey := cfg.JWT.Secret
-	claims := jwt.MapClaims{
-		"user_id": userID.String(),
-		"email":   reqBody.Email,
-		"exp":     time.Now().Add(cfg.Email.VerificationTTL).Unix(),
-		"iat":     time.Now().Unix(),
-		"purpose": "email_verification",
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	verificationToken, err := token.SignedString([]byte(secretKey))
+	verificationToken, err := tokens.Issue(tokens.PurposeEmailVerification, tokens.Claims{
+		UserID: userID.String(),
+		Email:  reqBody.Email,
+	}, cfg.Email.VerificationTTL)
 	if err != nil {
 		// http.Error(w, "Failed to generate verification token", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate verification token")
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/lockout"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// checkLoginThrottle answers 429 with a Retry-After header and returns false
+// while the account or the client IP is backing off or locked.
+func checkLoginThrottle(w http.ResponseWriter, r *http.Request, email string) bool {
+	wait, err := lockout.Get().Check(lockout.AccountKey(email), lockout.IPKey(utils.ClientIP(r)))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check login attempts")
+		return false
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		utils.ErrorResponse(w, http.StatusTooManyRequests, "Too many failed login attempts, try again later")
+		return false
+	}
+	return true
+}
+
+// recordLoginFailure counts a failed password or second factor against the account and the client IP
+func recordLoginFailure(r *http.Request, email string) {
+	if err := lockout.Get().Fail(lockout.AccountKey(email), lockout.IPKey(utils.ClientIP(r))); err != nil {
+		log.Println("Failed to record login failure for:", email, "Error:", err)
+	}
+}
+
+// recordLoginSuccess clears the failure counter of the account once a login is complete
+func recordLoginSuccess(email string) {
+	if err := lockout.Get().Succeed(lockout.AccountKey(email)); err != nil {
+		log.Println("Failed to reset login failures for:", email, "Error:", err)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/webauthn"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+type BeginPasskeyLoginRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+type FinishPasskeyLoginRequest struct {
+	MFAToken   string                      `json:"mfa_token"`
+	Credential webauthn.CredentialResponse `json:"credential"`
+}
+
+// BeginPasskeyLogin returns the options for navigator.credentials.get().
+// With an mfa_token from /auth/login the passkey is the second factor of that user,
+// without one it is a passwordless login with any discoverable credential.
+func BeginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req BeginPasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	var userID string
+	allow := []webauthn.CredentialDescriptor{}
+	userVerification := "required"
+
+	if req.MFAToken != "" {
+		claims, err := tokens.Parse(tokens.PurposeMFAPending, req.MFAToken)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+			return
+		}
+		userID = claims.UserID
+
+		allow, err = services.WebAuthnDescriptors(db, userID)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch passkeys")
+			return
+		}
+		if len(allow) == 0 {
+			utils.ErrorResponse(w, http.StatusBadRequest, "No passkey registered for this account")
+			return
+		}
+		// The password was already checked, presence is enough for the second factor
+		userVerification = "discouraged"
+	}
+
+	challenge, err := services.CreateWebAuthnChallenge(db, userID, webauthn.CeremonyAuthentication)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create challenge")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Passkey login started", webauthn.NewRequestOptions(challenge, allow, userVerification))
+}
+
+// FinishPasskeyLogin verifies the assertion and creates the session.
+func FinishPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req FinishPasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+	if req.Credential.ID == "" || req.Credential.RawID != req.Credential.ID {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid credential")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Burn the challenge
+	challenge, err := webauthn.Challenge(req.Credential)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client data")
+		return
+	}
+	challengeUserID, err := services.ConsumeWebAuthnChallenge(db, challenge, webauthn.CeremonyAuthentication)
+	if err == services.ErrChallengeNotFound {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired challenge")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check challenge")
+		return
+	}
+
+	// Step 2: Find the credential
+	credential, err := services.GetWebAuthnCredential(db, req.Credential.ID)
+	if err == services.ErrCredentialNotFound {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unknown passkey")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch passkey")
+		return
+	}
+
+	// Step 3: Tie the credential to the login, as second factor or passwordless
+	requireUserVerification := true
+	if req.MFAToken != "" {
+		claims, err := tokens.Parse(tokens.PurposeMFAPending, req.MFAToken)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired MFA token")
+			return
+		}
+		if claims.UserID != credential.UserID || challengeUserID != credential.UserID {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Passkey does not belong to this account")
+			return
+		}
+		requireUserVerification = false
+	} else if challengeUserID != "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "MFA token is required")
+		return
+	}
+	if req.Credential.Response.UserHandle != "" {
+		userHandle, err := webauthn.UserHandle(credential.UserID)
+		if err != nil || userHandle != req.Credential.Response.UserHandle {
+			utils.ErrorResponse(w, http.StatusUnauthorized, "Passkey does not belong to this account")
+			return
+		}
+	}
+
+	// Step 4: Verify the signature and the counter
+	signCount, err := webauthn.VerifyAssertion(req.Credential, challenge, credential.PublicKey, credential.Algorithm, credential.SignCount, requireUserVerification)
+	if err != nil {
+		log.Println("Passkey assertion failed for credential:", credential.ID, "Error:", err)
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Passkey verification failed")
+		return
+	}
+	recorded, err := services.RecordWebAuthnUse(db, credential.ID, credential.SignCount, signCount)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update passkey")
+		return
+	}
+	if !recorded {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Passkey verification failed")
+		return
+	}
+
+	// Step 5: Load the user and create the session
+	var user UserInfo
+	var emailVerified bool
+	err = db.QueryRow("SELECT id, username, email, user_type, email_verified FROM users WHERE id = $1", credential.UserID).
+		Scan(&user.ID, &user.Username, &user.Email, &user.Type, &emailVerified)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unknown passkey")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+	if !emailVerified {
+		utils.ErrorResponse(w, http.StatusForbidden, "Email not verified")
+		return
+	}
+
+	completeLogin(w, r, db, user)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ListSigningKeys lists the JWT signing keys without their private material
+func ListSigningKeys(w http.ResponseWriter, r *http.Request) {
+	// Connect to the database
+	db := database.Connect()
+
+	signingKeys, err := keys.List(db)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch signing keys")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Signing keys retrieved successfully", signingKeys)
+}
+
+// RotateSigningKey generates a new active signing key. The previous key keeps verifying until its tokens expire.
+func RotateSigningKey(w http.ResponseWriter, r *http.Request) {
+	// Connect to the database
+	db := database.Connect()
+
+	key, err := keys.Rotate(db, 0)
+	if err != nil {
+		log.Println("Failed to rotate signing key:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to rotate signing key")
+		return
+	}
+
+	log.Println("Signing key rotated by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusCreated, "Signing key rotated successfully", key)
+}
+
+// RetireSigningKey stops a key from verifying tokens right away
+func RetireSigningKey(w http.ResponseWriter, r *http.Request) {
+	// Get the kid from the URL path
+	kid := mux.Vars(r)["kid"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	err := keys.Retire(db, kid)
+	if err == keys.ErrKeyNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Signing key not found")
+		return
+	} else if err == keys.ErrActiveKey {
+		utils.ErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to retire signing key")
+		return
+	}
+
+	log.Println("Signing key", kid, "retired by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Signing key retired successfully", nil)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/lockout"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ListLockouts returns the accounts and client IPs that are currently blocked from logging in
+func ListLockouts(w http.ResponseWriter, r *http.Request) {
+	entries, err := lockout.Get().Blocked()
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch lockouts")
+		return
+	}
+
+	lockouts := make([]models.Lockout, 0, len(entries))
+	for _, entry := range entries {
+		kind, subject := lockout.Subject(entry.Key)
+		lockouts = append(lockouts, models.Lockout{
+			Type:              kind,
+			Subject:           subject,
+			Failures:          entry.Failures,
+			RetryAfterSeconds: int(math.Ceil(entry.RetryAfter.Seconds())),
+		})
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Lockouts fetched successfully", lockouts)
+}
+
+// UnlockIP clears the failure counter of a client IP
+func UnlockIP(w http.ResponseWriter, r *http.Request) {
+	ip := mux.Vars(r)["ip"]
+	if ip == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "IP address is required")
+		return
+	}
+
+	cleared, err := lockout.Get().UnlockIP(ip)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to unlock IP address")
+		return
+	}
+	if !cleared {
+		utils.ErrorResponse(w, http.StatusNotFound, "No failed logins recorded for this IP address")
+		return
+	}
+
+	log.Println("IP", ip, "unlocked by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "IP address unlocked successfully", nil)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// decodeCode reads the code from the request body, writing the error response when it is missing
+func decodeCode(w http.ResponseWriter, r *http.Request) (string, bool) {
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return "", false
+	}
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Code is required")
+		return "", false
+	}
+	return req.Code, true
+}
+
+// GetMyMFAStatus returns whether two-factor authentication is enabled for the current user
+func GetMyMFAStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := services.GetMFAStatus(database.Connect(), middleware.GetUserID(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch two-factor authentication status")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Two-factor authentication status fetched successfully", status)
+}
+
+// EnrollTOTP starts TOTP enrollment and returns the secret and the otpauth:// URI for the authenticator app
+func EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	// Connect to the database
+	db := database.Connect()
+
+	// The email is the account name shown in the authenticator app
+	var email string
+	if err := db.QueryRow("SELECT email FROM users WHERE id = $1", userID).Scan(&email); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	enrollment, err := services.StartTOTPEnrollment(db, userID, email)
+	if err == services.ErrMFAAlreadyEnabled {
+		utils.ErrorResponse(w, http.StatusConflict, "Two-factor authentication is already enabled")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to start two-factor authentication enrollment")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Scan the URI with your authenticator app and confirm with a code", enrollment)
+}
+
+// ConfirmTOTP enables two-factor authentication with the first code from the authenticator app.
+// The recovery codes in the response are never shown again.
+func ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	code, ok := decodeCode(w, r)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(r)
+
+	codes, err := services.ConfirmTOTPEnrollment(database.Connect(), userID, code)
+	switch err {
+	case nil:
+	case services.ErrMFANotEnrolled:
+		utils.ErrorResponse(w, http.StatusBadRequest, "Two-factor authentication enrollment was not started")
+		return
+	case services.ErrMFAAlreadyEnabled:
+		utils.ErrorResponse(w, http.StatusConflict, "Two-factor authentication is already enabled")
+		return
+	case services.ErrInvalidMFACode:
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid two-factor authentication code")
+		return
+	default:
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to enable two-factor authentication")
+		return
+	}
+
+	log.Println("Two-factor authentication enabled for user:", userID)
+	utils.SuccessResponse(w, http.StatusOK, "Two-factor authentication enabled", map[string][]string{
+		"recovery_codes": codes,
+	})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user, a valid code is required
+func RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	code, ok := decodeCode(w, r)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(r)
+
+	// Connect to the database
+	db := database.Connect()
+
+	valid, err := services.VerifyMFACode(db, userID, code)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to verify code")
+		return
+	}
+	if !valid {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid two-factor authentication code")
+		return
+	}
+
+	codes, err := services.RegenerateRecoveryCodes(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to generate recovery codes")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Recovery codes regenerated", map[string][]string{
+		"recovery_codes": codes,
+	})
+}
+
+// DisableMyMFA turns two-factor authentication off for the current user, a valid code is required
+func DisableMyMFA(w http.ResponseWriter, r *http.Request) {
+	code, ok := decodeCode(w, r)
+	if !ok {
+		return
+	}
+	userID := middleware.GetUserID(r)
+
+	// Connect to the database
+	db := database.Connect()
+
+	valid, err := services.VerifyMFACode(db, userID, code)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to verify code")
+		return
+	}
+	if !valid {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid two-factor authentication code")
+		return
+	}
+
+	if _, err := services.DisableMFA(db, userID); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to disable two-factor authentication")
+		return
+	}
+
+	log.Println("Two-factor authentication disabled for user:", userID)
+	utils.SuccessResponse(w, http.StatusOK, "Two-factor authentication disabled", nil)
+}
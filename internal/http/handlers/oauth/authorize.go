@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// Authorize is the authorization endpoint of the authorization-code flow.
+// The user must already be logged in, the browser carries the session in the auth cookie.
+// Registered clients are trusted, so there is no consent screen: the code goes straight back to the redirect URI.
+func Authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid authorization request")
+		return
+	}
+	clientID := r.Form.Get("client_id")
+	redirectURI := r.Form.Get("redirect_uri")
+	state := r.Form.Get("state")
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Check the client and the redirect URI. Until both are known good, errors are shown here and never redirected.
+	client, err := services.GetOAuthClient(db, clientID)
+	if err == services.ErrOAuthClientNotFound {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Unknown client")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch client")
+		return
+	}
+	if redirectURI == "" || !contains(client.RedirectURIs, redirectURI) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Redirect URI is not registered for this client")
+		return
+	}
+
+	// Step 2: Validate the request, errors now go back to the client
+	if !contains(client.GrantTypes, oidc.GrantAuthorizationCode) {
+		redirectError(w, r, redirectURI, state, errUnauthorizedClient, "The client may not use the authorization code flow")
+		return
+	}
+	if r.Form.Get("response_type") != "code" {
+		redirectError(w, r, redirectURI, state, errUnsupportedResponseType, "Only the authorization code flow is supported")
+		return
+	}
+	challenge := r.Form.Get("code_challenge")
+	if r.Form.Get("code_challenge_method") != oidc.CodeChallengeMethodS256 || !oidc.ValidCodeChallenge(challenge) {
+		redirectError(w, r, redirectURI, state, errInvalidRequest, "PKCE with code_challenge_method S256 is required")
+		return
+	}
+
+	// Step 3: Grant the scopes the client may ask for and the user holds. Service accounts cannot sign in to other clients.
+	// Only a signed in user can consent, tokens without a session such as personal access tokens cannot
+	userID := middleware.GetUserID(r)
+	if userID == "" || middleware.GetSessionID(r) == "" {
+		redirectError(w, r, redirectURI, state, errAccessDenied, "Only signed in users can authorize clients")
+		return
+	}
+	scopes, err := grantScopes(client, userID, oidc.ParseScope(r.Form.Get("scope")))
+	if err != nil {
+		redirectError(w, r, redirectURI, state, errServerError, "Failed to check scopes")
+		return
+	}
+	if len(scopes) == 0 {
+		redirectError(w, r, redirectURI, state, errInvalidScope, "None of the requested scopes can be granted")
+		return
+	}
+
+	// Step 4: Issue the code, bound to the client, redirect URI, session and PKCE challenge
+	code, err := services.IssueAuthorizationCode(db, services.AuthorizationCode{
+		ClientID:      client.ClientID,
+		UserID:        userID,
+		SessionID:     middleware.GetSessionID(r),
+		RedirectURI:   redirectURI,
+		Scope:         strings.Join(scopes, " "),
+		CodeChallenge: challenge,
+		Nonce:         r.Form.Get("nonce"),
+	})
+	if err != nil {
+		log.Println("Failed to issue authorization code for client", client.ClientID, "Error:", err)
+		redirectError(w, r, redirectURI, state, errServerError, "Failed to issue authorization code")
+		return
+	}
+
+	redirectWithParams(w, r, redirectURI, url.Values{"code": {code}}, state)
+}
+
+// redirectError sends an authorization error back to the client (RFC 6749 section 4.1.2.1)
+func redirectError(w http.ResponseWriter, r *http.Request, redirectURI, state, code, description string) {
+	redirectWithParams(w, r, redirectURI, url.Values{"error": {code}, "error_description": {description}}, state)
+}
+
+// redirectWithParams redirects to the client with the response parameters, the state and the issuer (RFC 9207) added to its query
+func redirectWithParams(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values, state string) {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid redirect URI")
+		return
+	}
+
+	query := target.Query()
+	for name, values := range params {
+		query[name] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	query.Set("iss", config.GetConfig().JWT.Issuer)
+	target.RawQuery = query.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// defaultClientScopes are allowed when a client of the authorization-code flow is registered without a scope list
+var defaultClientScopes = []string{oidc.ScopeOpenID, oidc.ScopeProfile, oidc.ScopeEmail}
+
+// CreateOAuthClient registers an application that signs users in through this service.
+// The client secret of confidential clients is only returned here.
+func CreateOAuthClient(w http.ResponseWriter, r *http.Request) {
+	var req models.OAuthClientRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+
+	// Validate required fields
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Name is required")
+		return
+	}
+	if len(req.GrantTypes) == 0 {
+		req.GrantTypes = []string{oidc.GrantAuthorizationCode}
+	}
+	for _, grantType := range req.GrantTypes {
+		if grantType != oidc.GrantAuthorizationCode && grantType != oidc.GrantClientCredentials {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Unsupported grant type: "+grantType)
+			return
+		}
+	}
+	// Service accounts authenticate with their secret alone, so they cannot be public
+	if req.Public && contains(req.GrantTypes, oidc.GrantClientCredentials) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Public clients cannot use the client_credentials grant")
+		return
+	}
+	if contains(req.GrantTypes, oidc.GrantAuthorizationCode) && len(req.RedirectURIs) == 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "At least one redirect URI is required for the authorization_code grant")
+		return
+	}
+	if req.RedirectURIs == nil {
+		req.RedirectURIs = []string{}
+	}
+	for _, redirectURI := range req.RedirectURIs {
+		if err := oidc.CheckRedirectURI(redirectURI); err != nil {
+			utils.ErrorResponse(w, http.StatusBadRequest, err.Error()+": "+redirectURI)
+			return
+		}
+	}
+	if len(req.AllowedScopes) == 0 && contains(req.GrantTypes, oidc.GrantAuthorizationCode) {
+		req.AllowedScopes = defaultClientScopes
+	}
+	if req.AllowedScopes == nil {
+		req.AllowedScopes = []string{}
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Scopes other than the standard ones are permission names
+	for _, scope := range req.AllowedScopes {
+		if oidc.IsStandardScope(scope) {
+			continue
+		}
+		var exists bool
+		if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM permissions WHERE name = $1)", scope).Scan(&exists); err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check scopes")
+			return
+		}
+		if !exists {
+			utils.ErrorResponse(w, http.StatusBadRequest, "Unknown scope: "+scope)
+			return
+		}
+	}
+
+	client, err := services.CreateOAuthClient(db, req, middleware.GetUserID(r))
+	if err != nil {
+		log.Println("Failed to register OAuth client:", err)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to register client")
+		return
+	}
+
+	log.Println("OAuth client", client.ClientID, "registered by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusCreated, "Client registered successfully, store the client secret now, it cannot be shown again", client)
+}
+
+// ListOAuthClients lists the registered clients without their secrets
+func ListOAuthClients(w http.ResponseWriter, r *http.Request) {
+	// Connect to the database
+	db := database.Connect()
+
+	clients, err := services.ListOAuthClients(db)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch clients")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Clients retrieved successfully", clients)
+}
+
+// DeleteOAuthClient removes a client, its pending authorization codes stop working right away
+func DeleteOAuthClient(w http.ResponseWriter, r *http.Request) {
+	// Get the client ID from the URL path
+	clientID := mux.Vars(r)["client_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	err := services.DeleteOAuthClient(db, clientID)
+	if err == services.ErrOAuthClientNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete client")
+		return
+	}
+
+	log.Println("OAuth client", clientID, "deleted by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Client deleted successfully", nil)
+}
+
+// AssignOAuthClientRole gives a service account the permissions of a role.
+// Callers can only hand out roles whose permissions they hold themselves.
+func AssignOAuthClientRole(w http.ResponseWriter, r *http.Request) {
+	clientID := mux.Vars(r)["client_id"]
+	roleID := mux.Vars(r)["role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Check the client and the role
+	client, err := services.GetOAuthClient(db, clientID)
+	if err == services.ErrOAuthClientNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch client")
+		return
+	}
+	if !contains(client.GrantTypes, oidc.GrantClientCredentials) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Only clients with the client_credentials grant can have roles")
+		return
+	}
+	rolePermissions, found, err := fetchRolePermissions(db, roleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return
+	}
+	if !found {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return
+	}
+
+	// Step 2: A service account must not end up with more power than the caller
+	callerPermissions, err := middleware.FetchRequestPermissions(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return
+	}
+	for _, perm := range rolePermissions {
+		if !middleware.CheckPermission([]string{perm}, callerPermissions) {
+			utils.ErrorResponse(w, http.StatusForbidden, "You cannot assign a role with permissions you do not hold: "+perm)
+			return
+		}
+	}
+
+	// Step 3: Assign the role
+	if err := services.AssignOAuthClientRole(db, client.ClientID, roleID, middleware.GetUserID(r)); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to assign role")
+		return
+	}
+
+	log.Println("Role", roleID, "assigned to OAuth client", client.ClientID, "by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Role assigned successfully", nil)
+}
+
+// RemoveOAuthClientRole takes a role away from a service account, its tokens lose the permissions right away
+func RemoveOAuthClientRole(w http.ResponseWriter, r *http.Request) {
+	clientID := mux.Vars(r)["client_id"]
+	roleID := mux.Vars(r)["role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	client, err := services.GetOAuthClient(db, clientID)
+	if err == services.ErrOAuthClientNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch client")
+		return
+	}
+	if _, err := uuid.Parse(roleID); err != nil {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client does not have this role")
+		return
+	}
+
+	removed, err := services.RemoveOAuthClientRole(db, client.ClientID, roleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to remove role")
+		return
+	}
+	if !removed {
+		utils.ErrorResponse(w, http.StatusNotFound, "Client does not have this role")
+		return
+	}
+
+	log.Println("Role", roleID, "removed from OAuth client", client.ClientID, "by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Role removed successfully", nil)
+}
+
+// fetchRolePermissions returns the permission names of a role and whether the role exists
+func fetchRolePermissions(db *sql.DB, roleID string) ([]string, bool, error) {
+	if _, err := uuid.Parse(roleID); err != nil {
+		return nil, false, nil
+	}
+
+	var exists bool
+	if err := db.QueryRow("SELECT EXISTS (SELECT 1 FROM roles WHERE id = $1)", roleID).Scan(&exists); err != nil || !exists {
+		return nil, false, err
+	}
+
+	rows, err := db.Query(`
+		SELECT DISTINCT p.name
+		FROM effective_role_permissions rp
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE rp.role_id = $1`,
+		roleID,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var perm string
+		if err := rows.Scan(&perm); err != nil {
+			return nil, false, err
+		}
+		permissions = append(permissions, perm)
+	}
+	return permissions, true, rows.Err()
+}
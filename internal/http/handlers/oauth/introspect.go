@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+)
+
+// IntrospectionResponse is the response of the introspection endpoint (RFC 7662 section 2.2).
+// Inactive tokens only get "active": false.
+type IntrospectionResponse struct {
+	Active      bool     `json:"active"`
+	Scope       string   `json:"scope,omitempty"`
+	ClientID    string   `json:"client_id,omitempty"`
+	Username    string   `json:"username,omitempty"`
+	TokenType   string   `json:"token_type,omitempty"`
+	ExpiresAt   int64    `json:"exp,omitempty"`
+	IssuedAt    int64    `json:"iat,omitempty"`
+	NotBefore   int64    `json:"nbf,omitempty"`
+	Subject     string   `json:"sub,omitempty"`
+	Audience    []string `json:"aud,omitempty"`
+	Issuer      string   `json:"iss,omitempty"`
+	JWTID       string   `json:"jti,omitempty"`
+	UserType    string   `json:"user_type,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// Introspect tells a confidential client whether a token is still active and whose it is, so resource servers such as
+// an API gateway can check tokens without the signing keys. Access tokens, OAuth access tokens and personal access tokens are accepted.
+func Introspect(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, http.StatusBadRequest, errInvalidRequest, "The request body must be form encoded")
+		return
+	}
+
+	// Step 1: Only clients with a secret may introspect tokens
+	client, ok := authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	if client.Public {
+		oauthError(w, http.StatusUnauthorized, errInvalidClient, "Public clients cannot introspect tokens")
+		return
+	}
+	token := strings.TrimSpace(r.PostForm.Get("token"))
+	if token == "" {
+		oauthError(w, http.StatusBadRequest, errInvalidRequest, "token is required")
+		return
+	}
+
+	// Step 2: Look the token up, token_type_hint is not needed since every kind is recognised by its format
+	response, err := introspectToken(database.Connect(), token)
+	if err != nil {
+		log.Println("Failed to introspect token for client", client.ClientID, "Error:", err)
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to introspect token")
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, response)
+}
+
+// introspectToken applies the same checks as the auth middleware and the userinfo endpoint. The permissions are those
+// the token would be authorized with, so tokens limited to a scope only list the permissions in it.
+func introspectToken(db *sql.DB, token string) (*IntrospectionResponse, error) {
+	inactive := &IntrospectionResponse{Active: false}
+
+	if strings.HasPrefix(token, services.PersonalAccessTokenPrefix) {
+		pat, err := services.LookupPersonalAccessToken(db, token)
+		if err == services.ErrInvalidPersonalAccessToken {
+			return inactive, nil
+		} else if err != nil {
+			return nil, err
+		}
+		scope := strings.Join(pat.Permissions, " ")
+		return introspectUser(db, pat.UserID, "", scope, true, &IntrospectionResponse{
+			Scope:     scope,
+			ExpiresAt: pat.ExpiresAt.Unix(),
+			IssuedAt:  pat.CreatedAt.Unix(),
+			JWTID:     pat.ID,
+		})
+	}
+
+	claims, err := tokens.Parse(tokens.PurposeAccess, token)
+	if err != nil {
+		if claims, err = tokens.Parse(tokens.PurposeOAuthAccess, token); err != nil {
+			return inactive, nil
+		}
+	}
+	revoked, err := services.IsTokenRevoked(db, claims.ID)
+	if err != nil || revoked {
+		return inactive, err
+	}
+
+	response := &IntrospectionResponse{
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Username:  claims.Username,
+		UserType:  claims.UserType,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		IssuedAt:  claims.IssuedAt.Unix(),
+		Subject:   claims.Subject,
+		Audience:  claims.Audience,
+		Issuer:    claims.Issuer,
+		JWTID:     claims.ID,
+	}
+	if claims.NotBefore != nil {
+		response.NotBefore = claims.NotBefore.Unix()
+	}
+
+	// Service account tokens work as long as the client keeps the client_credentials grant
+	if claims.UserID == "" && claims.ClientID != "" {
+		active, err := services.IsServiceAccountActive(db, claims.ClientID)
+		if err != nil || !active {
+			return inactive, err
+		}
+		permissions, err := middleware.FetchAllClientPermissions(claims.ClientID)
+		if err != nil {
+			return nil, err
+		}
+		if claims.Scope != "" {
+			permissions = middleware.LimitToScope(permissions, claims.Scope)
+		}
+		response.Active = true
+		response.TokenType = "Bearer"
+		response.Permissions = permissions
+		return response, nil
+	}
+
+	// User tokens die with their session
+	active, err := services.IsSessionActive(db, claims.SessionID, claims.UserID)
+	if err != nil || !active {
+		return inactive, err
+	}
+	return introspectUser(db, claims.UserID, claims.UserType, claims.Scope, claims.Scope != "", response)
+}
+
+// introspectUser completes the response for a token of an active user, a limited token only lists the permissions in its scope
+func introspectUser(db *sql.DB, userID, userType, scope string, limited bool, response *IntrospectionResponse) (*IntrospectionResponse, error) {
+	user, err := services.GetUser(db, userID)
+	if err == services.ErrUserNotFound || (err == nil && !user.Active) {
+		return &IntrospectionResponse{Active: false}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	permissions, err := middleware.FetchAllUserPermissions(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if limited {
+		permissions = middleware.LimitToScope(permissions, scope)
+	}
+
+	// OAuth access tokens and personal access tokens carry no user_type, the stored one is current anyway
+	if userType == "" {
+		userType = user.UserType
+	}
+
+	response.Active = true
+	response.TokenType = "Bearer"
+	response.Subject = user.ID
+	response.Username = user.Username
+	response.UserType = userType
+	response.Permissions = permissions
+	return response, nil
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+)
+
+// OAuth error codes from RFC 6749 and RFC 6750
+const (
+	errInvalidRequest          = "invalid_request"
+	errAccessDenied            = "access_denied"
+	errUnauthorizedClient      = "unauthorized_client"
+	errInvalidClient           = "invalid_client"
+	errInvalidGrant            = "invalid_grant"
+	errInvalidScope            = "invalid_scope"
+	errInvalidToken            = "invalid_token"
+	errInsufficientScope       = "insufficient_scope"
+	errUnsupportedGrantType    = "unsupported_grant_type"
+	errUnsupportedResponseType = "unsupported_response_type"
+	errServerError             = "server_error"
+)
+
+// writeOAuthJSON writes a protocol response. Their format is fixed by the OAuth and OpenID Connect specs,
+// so they are not wrapped in the usual response envelope, and they must never be cached.
+func writeOAuthJSON(w http.ResponseWriter, statusCode int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+// oauthError writes an error response as defined in RFC 6749 section 5.2
+func oauthError(w http.ResponseWriter, statusCode int, code, description string) {
+	writeOAuthJSON(w, statusCode, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// contains reports whether value is in list, compared exactly
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// grantScopes narrows the requested scopes to what the client may ask for and the user holds.
+// Permission scopes are only granted to users with that permission, standard scopes to everyone.
+func grantScopes(client *models.OAuthClient, userID string, requested []string) ([]string, error) {
+	var permissions []string
+	var granted []string
+	for _, scope := range requested {
+		if !oidc.HasScope(client.AllowedScopes, scope) {
+			continue
+		}
+		if oidc.IsStandardScope(scope) {
+			granted = append(granted, scope)
+			continue
+		}
+
+		if permissions == nil {
+			var err error
+			if permissions, err = middleware.FetchAllUserPermissions(userID); err != nil {
+				return nil, err
+			}
+		}
+		if middleware.CheckPermission([]string{scope}, permissions) {
+			granted = append(granted, scope)
+		}
+	}
+	return granted, nil
+}
+
+// loadUserClaims builds the claims about a user that the granted scopes release
+func loadUserClaims(db *sql.DB, userID string, scopes []string) (*models.User, oidc.UserClaims, error) {
+	user, err := services.GetUser(db, userID)
+	if err != nil {
+		return nil, oidc.UserClaims{}, err
+	}
+
+	var roles, permissions []string
+	if oidc.HasScope(scopes, oidc.ScopeRoles) {
+		if roles, err = services.UserRoles(db, userID); err != nil {
+			return nil, oidc.UserClaims{}, err
+		}
+	}
+	if oidc.HasScope(scopes, oidc.ScopePermissions) {
+		if permissions, err = middleware.FetchAllUserPermissions(userID); err != nil {
+			return nil, oidc.UserClaims{}, err
+		}
+	}
+
+	return user, oidc.NewUserClaims(*user, roles, permissions, scopes), nil
+}
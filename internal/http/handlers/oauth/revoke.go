@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+)
+
+// Revoke lets a client revoke an access token that was issued to it (RFC 7009), e.g. when the user signs out of the client.
+// Unknown, expired and already revoked tokens get the same empty success response.
+func Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, http.StatusBadRequest, errInvalidRequest, "The request body must be form encoded")
+		return
+	}
+
+	// Step 1: Authenticate the client, public clients can revoke their own tokens too
+	client, ok := authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	token := strings.TrimSpace(r.PostForm.Get("token"))
+	if token == "" {
+		oauthError(w, http.StatusBadRequest, errInvalidRequest, "token is required")
+		return
+	}
+
+	// Step 2: Find the token. Personal access tokens belong to users, who revoke them at /api/v1/me/tokens.
+	if strings.HasPrefix(token, services.PersonalAccessTokenPrefix) {
+		oauthError(w, http.StatusBadRequest, errUnauthorizedClient, "The token was not issued to this client")
+		return
+	}
+	claims, err := tokens.Parse(tokens.PurposeAccess, token)
+	if err != nil {
+		if claims, err = tokens.Parse(tokens.PurposeOAuthAccess, token); err != nil {
+			writeOAuthJSON(w, http.StatusOK, struct{}{})
+			return
+		}
+	}
+	if claims.ClientID != client.ClientID {
+		oauthError(w, http.StatusBadRequest, errUnauthorizedClient, "The token was not issued to this client")
+		return
+	}
+
+	// Step 3: Deny the token until it would have expired, allowing for the leeway of the parser
+	ttl := time.Until(claims.ExpiresAt.Time) + config.GetConfig().JWT.Leeway
+	if err := services.RevokeTokenID(database.Connect(), claims.ID, ttl); err != nil {
+		log.Println("Failed to revoke token for client", client.ClientID, "Error:", err)
+		oauthError(w, http.StatusServiceUnavailable, errServerError, "Failed to revoke token")
+		return
+	}
+
+	log.Println("Token", claims.ID, "revoked by OAuth client", client.ClientID)
+	writeOAuthJSON(w, http.StatusOK, struct{}{})
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+)
+
+// ServiceAccountUserType is the user_type claim of service account tokens
+const ServiceAccountUserType = "service_account"
+
+// TokenResponse is the successful response of the token endpoint (RFC 6749 section 5.1)
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	Scope       string `json:"scope,omitempty"`
+	IDToken     string `json:"id_token,omitempty"`
+}
+
+// Token is the token endpoint. Clients trade an authorization code and its PKCE verifier for an access token,
+// and for an ID token when the openid scope was granted. Service accounts get their own access token with client_credentials.
+func Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, http.StatusBadRequest, errInvalidRequest, "The request body must be form encoded")
+		return
+	}
+	grantType := r.PostForm.Get("grant_type")
+	if grantType != oidc.GrantAuthorizationCode && grantType != oidc.GrantClientCredentials {
+		oauthError(w, http.StatusBadRequest, errUnsupportedGrantType, "Only the authorization_code and client_credentials grants are supported")
+		return
+	}
+
+	// Authenticate the client, it must be allowed the grant
+	client, ok := authenticateClient(w, r)
+	if !ok {
+		return
+	}
+	if !contains(client.GrantTypes, grantType) {
+		oauthError(w, http.StatusBadRequest, errUnauthorizedClient, "The client may not use the "+grantType+" grant")
+		return
+	}
+
+	if grantType == oidc.GrantClientCredentials {
+		clientCredentialsGrant(w, r, client)
+		return
+	}
+	authorizationCodeGrant(w, r, client)
+}
+
+// authorizationCodeGrant trades an authorization code for tokens on behalf of the user who approved it
+func authorizationCodeGrant(w http.ResponseWriter, r *http.Request, client *models.OAuthClient) {
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Use up the code, it must have been issued to this client for this redirect URI
+	code, err := services.ConsumeAuthorizationCode(db, r.PostForm.Get("code"), client.ClientID, r.PostForm.Get("redirect_uri"))
+	if err == services.ErrInvalidGrant {
+		oauthError(w, http.StatusBadRequest, errInvalidGrant, "Invalid or expired authorization code")
+		return
+	} else if err != nil {
+		log.Println("Failed to consume authorization code:", err)
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to check authorization code")
+		return
+	}
+
+	// Step 2: Only the party that started the flow knows the PKCE verifier
+	if !oidc.VerifyCodeChallenge(code.CodeChallenge, r.PostForm.Get("code_verifier")) {
+		oauthError(w, http.StatusBadRequest, errInvalidGrant, "PKCE verification failed")
+		return
+	}
+
+	// Step 3: The user must still be logged in with the session that approved the code
+	active, err := services.IsSessionActive(db, code.SessionID, code.UserID)
+	if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to check session")
+		return
+	}
+	if !active {
+		oauthError(w, http.StatusBadRequest, errInvalidGrant, "The session behind the authorization code has ended")
+		return
+	}
+
+	// Step 4: Build the claims the granted scopes release
+	scopes := oidc.ParseScope(code.Scope)
+	user, userClaims, err := loadUserClaims(db, code.UserID, scopes)
+	if err == services.ErrUserNotFound {
+		oauthError(w, http.StatusBadRequest, errInvalidGrant, "User no longer exists")
+		return
+	} else if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to fetch user")
+		return
+	}
+	if !user.Active {
+		oauthError(w, http.StatusBadRequest, errInvalidGrant, "User is not active")
+		return
+	}
+
+	// Step 5: Issue the tokens
+	cfg := config.GetConfig()
+	accessToken, err := tokens.Issue(tokens.PurposeOAuthAccess, tokens.Claims{
+		UserID:    user.ID,
+		Username:  user.Username,
+		SessionID: code.SessionID,
+		ClientID:  client.ClientID,
+		Scope:     code.Scope,
+	}, cfg.OAuth.AccessTokenTTL)
+	if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to issue access token")
+		return
+	}
+
+	response := TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(cfg.OAuth.AccessTokenTTL.Seconds()),
+		Scope:       code.Scope,
+	}
+	if oidc.HasScope(scopes, oidc.ScopeOpenID) {
+		response.IDToken, err = oidc.IssueIDToken(user.ID, client.ClientID, code.Nonce, code.AuthTime, userClaims)
+		if err != nil {
+			oauthError(w, http.StatusInternalServerError, errServerError, "Failed to issue ID token")
+			return
+		}
+	}
+
+	writeOAuthJSON(w, http.StatusOK, response)
+}
+
+// clientCredentialsGrant issues an access token to a service account. The token carries no user, the API authorizes it
+// with the permissions of the roles of the client. A scope narrows the token to some of those permissions.
+func clientCredentialsGrant(w http.ResponseWriter, r *http.Request, client *models.OAuthClient) {
+	var scope string
+	if requested := oidc.ParseScope(r.PostForm.Get("scope")); len(requested) > 0 {
+		permissions, err := middleware.FetchAllClientPermissions(client.ClientID)
+		if err != nil {
+			oauthError(w, http.StatusInternalServerError, errServerError, "Failed to fetch permissions")
+			return
+		}
+		var granted []string
+		for _, s := range requested {
+			if middleware.CheckPermission([]string{s}, permissions) {
+				granted = append(granted, s)
+			}
+		}
+		if len(granted) == 0 {
+			oauthError(w, http.StatusBadRequest, errInvalidScope, "The client holds none of the requested permissions")
+			return
+		}
+		scope = strings.Join(granted, " ")
+	}
+
+	cfg := config.GetConfig()
+	accessToken, err := tokens.Issue(tokens.PurposeAccess, tokens.Claims{
+		Username: client.Name,
+		UserType: ServiceAccountUserType,
+		ClientID: client.ClientID,
+		Scope:    scope,
+	}, cfg.OAuth.AccessTokenTTL)
+	if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to issue access token")
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, TokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(cfg.OAuth.AccessTokenTTL.Seconds()),
+		Scope:       scope,
+	})
+}
+
+// authenticateClient reads the client credentials from HTTP Basic auth (client_secret_basic) or the form (client_secret_post).
+// Public clients send only their client_id. It writes the error response itself and reports whether to go on.
+func authenticateClient(w http.ResponseWriter, r *http.Request) (*models.OAuthClient, bool) {
+	clientID, secret, basic := r.BasicAuth()
+	if basic {
+		// RFC 6749 section 2.3.1: both parts are form encoded before they go into the header
+		var err error
+		if clientID, err = url.QueryUnescape(clientID); err == nil {
+			secret, err = url.QueryUnescape(secret)
+		}
+		if err != nil || r.PostForm.Get("client_secret") != "" {
+			oauthError(w, http.StatusBadRequest, errInvalidRequest, "Malformed client credentials")
+			return nil, false
+		}
+	} else {
+		clientID = r.PostForm.Get("client_id")
+		secret = r.PostForm.Get("client_secret")
+	}
+
+	client, err := services.AuthenticateOAuthClient(database.Connect(), clientID, secret)
+	if err == services.ErrInvalidClient {
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+		oauthError(w, http.StatusUnauthorized, errInvalidClient, "Client authentication failed")
+		return nil, false
+	} else if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to authenticate client")
+		return nil, false
+	}
+	return client, true
+}
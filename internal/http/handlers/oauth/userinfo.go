@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+)
+
+// UserInfoResponse is the response of the userinfo endpoint
+type UserInfoResponse struct {
+	Subject string `json:"sub"`
+	oidc.UserClaims
+}
+
+// UserInfo returns the claims about the user that the scopes of the OAuth access token release
+func UserInfo(w http.ResponseWriter, r *http.Request) {
+	// Step 1: Only OAuth access tokens from the Authorization header are accepted (RFC 6750)
+	scheme, tokenString, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo"`)
+		oauthError(w, http.StatusUnauthorized, errInvalidRequest, "Bearer access token is required")
+		return
+	}
+	claims, err := tokens.Parse(tokens.PurposeOAuthAccess, strings.TrimSpace(tokenString))
+	if err != nil {
+		bearerError(w, http.StatusUnauthorized, errInvalidToken, "Invalid or expired access token")
+		return
+	}
+	scopes := oidc.ParseScope(claims.Scope)
+	if !oidc.HasScope(scopes, oidc.ScopeOpenID) {
+		bearerError(w, http.StatusForbidden, errInsufficientScope, "The openid scope is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 2: Tokens die with the session that approved them, or when the client revokes them
+	revoked, err := services.IsTokenRevoked(db, claims.ID)
+	if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to check token")
+		return
+	}
+	if revoked {
+		bearerError(w, http.StatusUnauthorized, errInvalidToken, "Access token has been revoked")
+		return
+	}
+	active, err := services.IsSessionActive(db, claims.SessionID, claims.UserID)
+	if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to check session")
+		return
+	}
+	if !active {
+		bearerError(w, http.StatusUnauthorized, errInvalidToken, "Session has been revoked")
+		return
+	}
+
+	// Step 3: Build the claims
+	user, userClaims, err := loadUserClaims(db, claims.UserID, scopes)
+	if err == services.ErrUserNotFound {
+		bearerError(w, http.StatusUnauthorized, errInvalidToken, "User no longer exists")
+		return
+	} else if err != nil {
+		oauthError(w, http.StatusInternalServerError, errServerError, "Failed to fetch user")
+		return
+	}
+
+	writeOAuthJSON(w, http.StatusOK, UserInfoResponse{Subject: user.ID, UserClaims: userClaims})
+}
+
+// bearerError rejects a request to a resource protected by an access token (RFC 6750 section 3)
+func bearerError(w http.ResponseWriter, statusCode int, code, description string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="userinfo", error="`+code+`", error_description="`+description+`"`)
+	oauthError(w, statusCode, code, description)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/lockout"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// ChangeMyPassword changes the password of the authenticated user.
+// Every other session is logged out and the user gets a notification email.
+func ChangeMyPassword(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.CurrentPassword == "" || req.NewPassword == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Current password and new password are required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Load the account
+	var username, email, storedHash string
+	err := db.QueryRow("SELECT username, email, password_hash FROM users WHERE id = $1", userID).
+		Scan(&username, &email, &storedHash)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	// Step 2: Check the current password, wrong guesses count towards the login lockout
+	guard := lockout.Get()
+	accountKey, ipKey := lockout.AccountKey(email), lockout.IPKey(utils.ClientIP(r))
+	wait, err := guard.Check(accountKey, ipKey)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check login attempts")
+		return
+	}
+	if wait > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		utils.ErrorResponse(w, http.StatusTooManyRequests, "Too many failed attempts, try again later")
+		return
+	}
+
+	match, _, err := password.Verify(req.CurrentPassword, storedHash)
+	if err != nil {
+		log.Println("Failed to verify password hash for user:", userID, "Error:", err)
+	}
+	if !match {
+		if err := guard.Fail(accountKey, ipKey); err != nil {
+			log.Println("Failed to record password failure for:", email, "Error:", err)
+		}
+		utils.ErrorResponse(w, http.StatusBadRequest, "Current password is incorrect")
+		return
+	}
+
+	// Step 3: Apply the password policy, the new password must differ from the current one even without history
+	violations, err := services.CheckNewPassword(db, userID, req.NewPassword, password.Subject{Username: username, Email: email})
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check password policy")
+		return
+	}
+	if same, _, _ := password.Verify(req.NewPassword, storedHash); same && len(violations) == 0 {
+		violations = append(violations, password.Violation{Code: password.ViolationReused, Message: "New password must differ from the current password"})
+	}
+	if len(violations) > 0 {
+		utils.ErrorResponseWithData(w, http.StatusBadRequest, "Password does not meet the password policy", map[string]interface{}{
+			"violations": violations,
+		})
+		return
+	}
+
+	// Step 4: Store the new hash, a password changed concurrently wins
+	hashedPassword, err := password.Hash(req.NewPassword)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to hash password")
+		return
+	}
+	res, err := db.Exec(`
+		UPDATE users SET password_hash = $1, must_change_password = FALSE, updated_at = NOW()
+		WHERE id = $2 AND password_hash = $3`,
+		hashedPassword, userID, storedHash,
+	)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update password")
+		return
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		utils.ErrorResponse(w, http.StatusConflict, "Password was changed by another request")
+		return
+	}
+
+	if err := services.RecordPasswordHistory(db, userID, hashedPassword); err != nil {
+		log.Println("Failed to record password history for user:", userID, "Error:", err)
+	}
+	if err := guard.Succeed(accountKey); err != nil {
+		log.Println("Failed to reset login failures for:", email, "Error:", err)
+	}
+
+	// Step 5: Log out everywhere else
+	revoked, err := services.RevokeAllSessions(db, userID, middleware.GetSessionID(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Password changed but failed to revoke other sessions")
+		return
+	}
+
+	// Step 6: Tell the owner, the password is already changed so a failed email is only logged
+	if err := sendPasswordChangedEmail(email, username, utils.ClientIP(r)); err != nil {
+		log.Println("Failed to send password changed email to:", email, "Error:", err)
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Password changed successfully", map[string]int64{
+		"revoked_sessions": revoked,
+	})
+}
+
+// sendPasswordChangedEmail notifies the owner of an account that its password was changed
+func sendPasswordChangedEmail(toEmail, username, ipAddress string) error {
+	cfg := config.GetConfig()
+
+	subject := "Your password was changed"
+	body := fmt.Sprintf(`
+		<html>
+		<body style="font-family: Arial, sans-serif; line-height: 1.6; color: #333;">
+			<h2>Your password was changed</h2>
+			<p>Hello %s,</p>
+			<p>The password of your account was changed on %s from IP address %s.</p>
+			<p>All other sessions were logged out.</p>
+			<p>If you did not make this change, reset your password immediately and contact an administrator.</p>
+			<p>Thank you.</p>
+		</body>
+		</html>
+	`, html.EscapeString(username), time.Now().UTC().Format(time.RFC1123), html.EscapeString(ipAddress))
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-version: 1.0;\r\nContent-Type: text/html; charset=\"UTF-8\";\r\n\r\n%s",
+		cfg.Email.From, toEmail, subject, body)
+
+	auth := smtp.PlainAuth("", cfg.Email.Username, cfg.Email.Password, cfg.Email.Host)
+	return smtp.SendMail(
+		fmt.Sprintf("%s:%d", cfg.Email.Host, cfg.Email.Port),
+		auth,
+		cfg.Email.From,
+		[]string{toEmail},
+		[]byte(message),
+	)
+}
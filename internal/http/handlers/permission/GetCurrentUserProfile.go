@@ -4,63 +4,39 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
 // GetCurrentUserProfile returns the current authenticated user's profile
 func GetCurrentUserProfile(w http.ResponseWriter, r *http.Request) {
-	// Get the cookie
-	cookie, err := r.Cookie("auth_token")
+	// Get the token from the Authorization header or the cookie
+	tokenString, err := middleware.ExtractToken(r)
 	if err != nil {
 		// http.Error(w, "No valid authentication token", http.StatusUnauthorized)
 		utils.ErrorResponse(w, http.StatusUnauthorized, "No valid authentication token")
 		return
 	}
 
-	// Load the config
-	cfg := config.GetConfig()
-	// if err != nil {
-	// 	http.Error(w, "Failed to load config", http.StatusInternalServerError)
-	// 	return
-	// }
-
 	// Parse the JWT token
-	tokenString := cookie.Value
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return []byte(cfg.JWT.Secret), nil
-	})
-
-	if err != nil || !token.Valid {
+	claims, err := tokens.Parse(tokens.PurposeAccess, tokenString)
+	if err != nil {
 		// http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token")
 		return
 	}
 
-	// Extract the token claims
-	if claims, ok := token.Claims.(jwt.MapClaims); ok {
-		userID := claims["user_id"]
-		username := claims["username"]
-		userType := claims["user_type"]
-		exp := claims["exp"]
-
-		expirationTime := time.Unix(int64(exp.(float64)), 0)
-
-		// Build the response
-		response := map[string]interface{}{
-			"user_id":    userID,
-			"username":   username,
-			"user_type":  userType,
-			"expires_at": expirationTime.Format(time.RFC3339),
-			"who_am_i":   userType,
-		}
-
-		// w.Header().Set("Content-Type", "application/json")
-		// json.NewEncoder(w).Encode(response["who_am_i"])
-		utils.SuccessResponse(w, http.StatusOK, "Success", response["who_am_i"])
-	} else {
-		// http.Error(w, "Invalid token claims", http.StatusUnauthorized)
-		utils.ErrorResponse(w, http.StatusUnauthorized, "Invalid token claims")
+	// Build the response
+	response := map[string]interface{}{
+		"user_id":    claims.UserID,
+		"username":   claims.Username,
+		"user_type":  claims.UserType,
+		"expires_at": claims.ExpiresAt.Time.Format(time.RFC3339),
+		"who_am_i":   claims.UserType,
 	}
+
+	// w.Header().Set("Content-Type", "application/json")
+	// json.NewEncoder(w).Encode(response["who_am_i"])
+	utils.SuccessResponse(w, http.StatusOK, "Success", response["who_am_i"])
 }
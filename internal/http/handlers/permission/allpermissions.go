@@ -24,9 +24,9 @@ func GetCurrentUserPermissions(w http.ResponseWriter, r *http.Request) {
 
 	// Fetch the user's permissions
 	query := `
-	SELECT p.id, p.name, p.description, p.resource, p.action
+	SELECT DISTINCT p.id, p.name, p.description, p.resource, p.action
 	FROM user_roles ur
-	INNER JOIN role_permissions rp ON ur.role_id = rp.role_id
+	INNER JOIN effective_role_permissions rp ON ur.role_id = rp.role_id
 	INNER JOIN permissions p ON rp.permission_id = p.id
 	WHERE ur.user_id = $1
 	ORDER BY p.name ASC
@@ -14,16 +14,7 @@ import (
 
 // CreatePermission handles creating a new permission
 func CreatePermission(w http.ResponseWriter, r *http.Request) {
-	// Extract user info from the context
-	userType := middleware.GetUserType(r)
-
-	// Allow only Admin and SystemAdmin
-	if userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "No permission to access this resource", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this resource")
-		return
-	}
-
+	// The route checks the permission, custom roles holding it get through too
 	// Parse the request body
 	var req models.PermissionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -33,9 +24,19 @@ func CreatePermission(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
-	if req.Name == "" || req.Resource == "" || req.Action == "" {
-		// http.Error(w, "Name, resource, and action are required", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "Name, resource, and action are required")
+	if msg := checkPermissionRequest(&req); msg != "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	// Only permissions the caller holds can be created, a wildcard such as * would otherwise be a way to any grant
+	allowed, err := middleware.HasPermission(r, req.Name)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return
+	}
+	if !allowed {
+		utils.ErrorResponse(w, http.StatusForbidden, "You cannot create a permission you do not hold: "+req.Name)
 		return
 	}
 
@@ -52,7 +53,7 @@ func CreatePermission(w http.ResponseWriter, r *http.Request) {
 	permissionID := uuid.NewString()
 	createdAt := time.Now()
 
-	_, err := db.Exec(query, permissionID, req.Name, req.Description, req.Resource, req.Action, createdAt, createdAt)
+	_, err = db.Exec(query, permissionID, req.Name, req.Description, req.Resource, req.Action, createdAt, createdAt)
 	if err != nil {
 		http.Error(w, "Failed to create permission", http.StatusInternalServerError)
 		return
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// UpdatePermission replaces the name, description, resource and action of a permission.
+// Permissions the routes require keep their name, and the caller can only rename to a permission they hold.
+func UpdatePermission(w http.ResponseWriter, r *http.Request) {
+	// Get the permission ID from the URL
+	permissionID := mux.Vars(r)["permission_id"]
+
+	var req models.PermissionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+
+	// Validate required fields
+	if msg := checkPermissionRequest(&req); msg != "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: A route requiring the old name would become unreachable
+	current, err := services.GetPermission(db, permissionID)
+	if err == services.ErrPermissionNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Permission not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permission details")
+		return
+	}
+	if current.Name != req.Name && middleware.IsRoutePermission(current.Name) {
+		utils.ErrorResponse(w, http.StatusConflict, "Permission "+current.Name+" is required by the API and cannot be renamed")
+		return
+	}
+
+	// A rename grants the new name to every role holding the permission, so the caller must hold it already.
+	// This keeps anyone from renaming a permission they may edit to * or another broader name.
+	if current.Name != req.Name {
+		allowed, err := middleware.HasPermission(r, req.Name)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+			return
+		}
+		if !allowed {
+			utils.ErrorResponse(w, http.StatusForbidden, "You cannot rename a permission to one you do not hold: "+req.Name)
+			return
+		}
+	}
+
+	// Step 2: Update the permission
+	permission, err := services.UpdatePermission(db, permissionID, req)
+	if err == services.ErrPermissionNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Permission not found")
+		return
+	} else if err == services.ErrPermissionExists {
+		utils.ErrorResponse(w, http.StatusConflict, "A permission with this name already exists")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update permission")
+		return
+	}
+
+	log.Println("Permission", current.Name, "updated to", permission.Name, "by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Permission updated successfully", permission)
+}
+
+// DeletePermission deletes a permission and takes it away from every role.
+// Permissions the routes require cannot be deleted.
+func DeletePermission(w http.ResponseWriter, r *http.Request) {
+	// Get the permission ID from the URL
+	permissionID := mux.Vars(r)["permission_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	permission, err := services.GetPermission(db, permissionID)
+	if err == services.ErrPermissionNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Permission not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permission details")
+		return
+	}
+	if middleware.IsRoutePermission(permission.Name) {
+		utils.ErrorResponse(w, http.StatusConflict, "Permission "+permission.Name+" is required by the API and cannot be deleted")
+		return
+	}
+
+	err = services.DeletePermission(db, permissionID)
+	if err == services.ErrPermissionNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Permission not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete permission")
+		return
+	}
+
+	log.Println("Permission", permission.Name, "deleted by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Permission deleted successfully", nil)
+}
+
+// ListPermissionUsage lists every permission with the roles that grant it and how many users hold it
+func ListPermissionUsage(w http.ResponseWriter, r *http.Request) {
+	// Connect to the database
+	db := database.Connect()
+
+	usage, err := services.ListPermissionUsage(db)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permission usage")
+		return
+	}
+	for i := range usage {
+		usage[i].RequiredByRoutes = middleware.IsRoutePermission(usage[i].Name)
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Permission usage retrieved successfully", usage)
+}
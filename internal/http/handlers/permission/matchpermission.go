@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/matcher"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// MatchPermission tests whether a grant, wildcards and scopes included, covers a required permission.
+// Neither has to exist, so a wildcard grant can be tried out before it is created.
+func MatchPermission(w http.ResponseWriter, r *http.Request) {
+	var req models.PermissionMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+
+	// Validate required fields
+	req.Grant = strings.TrimSpace(req.Grant)
+	req.Required = strings.TrimSpace(req.Required)
+	if req.Grant == "" || req.Required == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Grant and required are required")
+		return
+	}
+
+	match := models.PermissionMatch{
+		Grant:    req.Grant,
+		Required: req.Required,
+		Covers:   matcher.Covers(req.Grant, req.Required),
+	}
+	utils.SuccessResponse(w, http.StatusOK, "Permission match tested successfully", match)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/matcher"
+)
+
+// checkPermissionRequest trims the request and checks its resource and action against the name.
+// A name reads resource:action, the resource is everything before the first colon and the action the rest,
+// so user:read:all has the resource user and the action read:all. "*" alone has both set to "*".
+// It returns what is wrong with the request, empty when it is valid.
+func checkPermissionRequest(req *models.PermissionRequest) string {
+	req.Name = strings.TrimSpace(req.Name)
+	req.Resource = strings.TrimSpace(req.Resource)
+	req.Action = strings.TrimSpace(req.Action)
+	if req.Name == "" || req.Resource == "" || req.Action == "" {
+		return "Name, resource, and action are required"
+	}
+	if strings.ContainsFunc(req.Name, unicode.IsSpace) {
+		return "Name must not contain spaces"
+	}
+
+	resource, action, ok := strings.Cut(req.Name, ":")
+	if req.Name == matcher.Wildcard {
+		resource, action, ok = matcher.Wildcard, matcher.Wildcard, true
+	}
+	if !ok || resource == "" || action == "" {
+		return "Name must read resource:action"
+	}
+	if req.Resource != resource || req.Action != action {
+		return "Resource and action must match the name, expected " + resource + " and " + action
+	}
+	return ""
+}
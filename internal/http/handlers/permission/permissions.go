@@ -6,22 +6,12 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
-	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
 // ListAllPermissions lists all the permissions (Admin+)
 func ListAllPermissions(w http.ResponseWriter, r *http.Request) {
-	// Extract the user type from the context
-	userType := middleware.GetUserType(r)
-
-	// Allow only Admin and SystemAdmin
-	if userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "No permission to access this resource", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this resource")
-		return
-	}
-
+	// The route checks the permission, custom roles holding it get through too
 	// Connect to the database
 	db := database.Connect()
 
@@ -73,16 +63,7 @@ func ListAllPermissions(w http.ResponseWriter, r *http.Request) {
 }
 
 func GetPermissionDetails(w http.ResponseWriter, r *http.Request) {
-	// Extract the user type from the context
-	userType := middleware.GetUserType(r)
-
-	// Allow only Admin and SystemAdmin
-	if userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "No permission to access this resource", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this resource")
-		return
-	}
-
+	// The route checks the permission, custom roles holding it get through too
 	// Get the permission ID from the URL
 	vars := mux.Vars(r)
 	permissionID := vars["permission_id"]
@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
@@ -17,7 +18,6 @@ type ChangeRoleRequest struct {
 
 func ChangeUserRole(w http.ResponseWriter, r *http.Request) {
 	userID := mux.Vars(r)["user_id"]
-	currentUserType := middleware.GetUserType(r)
 
 	if userID == "" {
 		// http.Error(w, "User ID is required", http.StatusBadRequest)
@@ -25,9 +25,9 @@ func ChangeUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if currentUserType != "admin" && currentUserType != "system_admin" {
-		// http.Error(w, "Only Admin or System Admin can change the role", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "Only Admin or System Admin can change the role")
+	// user_roles records who assigned a role, which must be a user
+	if middleware.GetUserID(r) == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only users can change roles")
 		return
 	}
 
@@ -41,10 +41,9 @@ func ChangeUserRole(w http.ResponseWriter, r *http.Request) {
 	// Connect to the database
 	db := database.Connect()
 
-	// Fetch the current user type
-	var currentRole string
-	err := db.QueryRow("SELECT user_type FROM users WHERE id = $1", userID).Scan(&currentRole)
-	if err == sql.ErrNoRows {
+	// Fetch the current primary role
+	currentRole, err := services.GetUserPrimaryRole(db, userID)
+	if err == services.ErrUserNotFound {
 		// http.Error(w, "User not found", http.StatusNotFound)
 		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
 		return
@@ -74,17 +73,30 @@ func ChangeUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update the user's main role in the users table
-	_, err = db.Exec("UPDATE users SET user_type = $1, updated_at = NOW() WHERE id = $2", req.RoleName, userID)
+	// Changing a role must not reach beyond the caller's own permissions
+	rolePermissions, err := services.ListEffectiveRolePermissions(db, roleID)
 	if err != nil {
-		// http.Error(w, "Failed to update user type", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update user type")
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role permissions")
 		return
 	}
-
-	// Update the user's role in the user_roles table
-	_, err = db.Exec("UPDATE user_roles SET role_id = $1 WHERE user_id = $2", roleID, userID)
+	callerPermissions, err := middleware.FetchRequestPermissions(r)
 	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return
+	}
+	for _, perm := range rolePermissions {
+		if !middleware.CheckPermission([]string{perm.Name}, callerPermissions) {
+			utils.ErrorResponse(w, http.StatusForbidden, "You cannot assign a role with permissions you do not hold: "+perm.Name)
+			return
+		}
+	}
+
+	// Make it the only role of the user, user_type follows as the primary role
+	err = services.SetUserRole(db, userID, roleID, middleware.GetUserID(r))
+	if err == services.ErrLastSystemAdmin {
+		utils.ErrorResponse(w, http.StatusForbidden, "The last System Admin cannot lose the role")
+		return
+	} else if err != nil {
 		// http.Error(w, "Failed to update user role", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update user role")
 		return
@@ -7,7 +7,6 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
-	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
 	"github.com/sagorsarker04/Developer-Assignment/internal/models"
 		"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
@@ -71,13 +70,6 @@ func CreateRole(w http.ResponseWriter, r *http.Request) {
 	// w.Header().Set("Content-Type", "application/json")
 	// json.NewEncoder(w).Encode(response)
 
-	userType := middleware.GetUserType(r)
-
-	if userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "You cannot access this page", http.StatusBadGateway)
-		utils.ErrorResponse(w, http.StatusBadGateway, "You cannot access this page")
-		return
-	}
 	var req models.CreateRoleRequest
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
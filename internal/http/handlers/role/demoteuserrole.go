@@ -1,13 +1,13 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 		"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
@@ -23,14 +23,6 @@ func DemoteUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Only system_admin and admin can demote
-	userType := middleware.GetUserType(r)
-	if userType != "system_admin" && userType != "admin" {
-		// http.Error(w, "Only System Admin and Admin can demote users", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "Only System Admin and Admin can demote users")
-		return
-	}
-
 	var req DemoteRoleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
 		// http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -41,9 +33,8 @@ func DemoteUserRole(w http.ResponseWriter, r *http.Request) {
 	// Connect to the database
 	db := database.Connect()
 
-	var currentRole string
-	err := db.QueryRow("SELECT user_type FROM users WHERE id = $1", userID).Scan(&currentRole)
-	if err == sql.ErrNoRows {
+	currentRole, err := services.GetUserPrimaryRole(db, userID)
+	if err == services.ErrUserNotFound {
 		// http.Error(w, "User not found", http.StatusNotFound)
 		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
 		return
@@ -92,14 +83,6 @@ func DemoteUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update users table
-	_, err = db.Exec("UPDATE users SET user_type = $1 WHERE id = $2", targetRole, userID)
-	if err != nil {
-		// http.Error(w, "Cannot update user", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "Cannot update user")
-		return
-	}
-
 	// Get role ID of the target role
 	var newRoleID string
 	err = db.QueryRow("SELECT id FROM roles WHERE name = $1", targetRole).Scan(&newRoleID)
@@ -109,8 +92,8 @@ func DemoteUserRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Update user_roles table
-	_, err = db.Exec("UPDATE user_roles SET role_id = $1 WHERE user_id = $2", newRoleID, userID)
+	// Replace the role on the promotion ladder, other roles are kept and user_type follows
+	err = services.SetUserBuiltinRole(db, userID, newRoleID, middleware.GetUserID(r))
 	if err != nil {
 		// http.Error(w, "Failed to update user role", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update user role")
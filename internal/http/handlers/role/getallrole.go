@@ -2,27 +2,15 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
-	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
 func GetAllRole(w http.ResponseWriter, r *http.Request) {
-	// Extract user info from the context
-	userType := middleware.GetUserType(r)
-	fmt.Println("User Type:", userType)
-
-	// Allow only Admin and SystemAdmin
-	if userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "No permission to access this resource", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this resource")
-		return
-	}
-
+	// The route checks the permission, custom roles holding it get through too
 	// Connect to the database
 	db := database.Connect()
 
@@ -7,22 +7,12 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
-	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
 		"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
 // GetRoleDetails returns the details of a specific role
 func GetRoleDetails(w http.ResponseWriter, r *http.Request) {
-	// Extract user info from the context
-	userType := middleware.GetUserType(r)
-
-	// Allow only Admin and SystemAdmin
-	if userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "No permission to access this resource", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this resource")
-		return
-	}
-
+	// The route checks the permission, custom roles holding it get through too
 	// Get the role_id from the URL path
 	vars := mux.Vars(r)
 	roleID := vars["role_id"]
@@ -7,6 +7,8 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
@@ -34,8 +36,14 @@ func PromoteToModerator(w http.ResponseWriter, r *http.Request) {
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch moderator role")
 		return
 	}
-	var currentRole string
-	db.QueryRow("SELECT user_type FROM users WHERE id = $1", userID).Scan(&currentRole)
+	currentRole, err := services.GetUserPrimaryRole(db, userID)
+	if err == services.ErrUserNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user role")
+		return
+	}
 	fmt.Println(currentRole)
 
 	//user chara kaoke moderator korte parbona
@@ -44,17 +52,16 @@ func PromoteToModerator(w http.ResponseWriter, r *http.Request) {
 		utils.ErrorResponse(w, http.StatusNotFound, "Admins cannot be promoted to Moderator!")
 		return
 	}
-	// Update the user's main role in the users table
-	_, err = db.Exec("UPDATE users SET user_type = 'moderator', updated_at = NOW() WHERE id = $1", userID)
-	if err != nil {
-		// http.Error(w, "Failed to update user type", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update user type")
+	// Replace the user role with Moderator, roles outside the promotion ladder are kept
+	if middleware.GetUserID(r) == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only users can change roles")
 		return
 	}
-
-	// Update the user's role in the user_roles table
-	_, err = db.Exec("UPDATE user_roles SET role_id = $1 WHERE user_id = $2", roleID, userID)
-	if err != nil {
+	err = services.SetUserBuiltinRole(db, userID, roleID, middleware.GetUserID(r))
+	if err == services.ErrLastSystemAdmin {
+		utils.ErrorResponse(w, http.StatusForbidden, "The last System Admin cannot lose the role")
+		return
+	} else if err != nil {
 		// http.Error(w, "Failed to update user role", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update user role")
 		return
@@ -7,18 +7,12 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
 func PromoteToAdmin(w http.ResponseWriter, r *http.Request) {
 	userID := mux.Vars(r)["user_id"]
-	currentUserType := middleware.GetUserType(r)
-
-	if currentUserType != "system_admin" {
-		// http.Error(w, "Tumi to system admin na vai", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "Tumi to system admin na vai")
-		return
-	}
 
 	if userID == "" {
 		// http.Error(w, "User ID is required", http.StatusBadRequest)
@@ -30,9 +24,8 @@ func PromoteToAdmin(w http.ResponseWriter, r *http.Request) {
 	db := database.Connect()
 
 	// Check if the user is already an Admin or SystemAdmin
-	var currentRole string
-	err := db.QueryRow("SELECT user_type FROM users WHERE id = $1", userID).Scan(&currentRole)
-	if err == sql.ErrNoRows {
+	currentRole, err := services.GetUserPrimaryRole(db, userID)
+	if err == services.ErrUserNotFound {
 		// http.Error(w, "User not found", http.StatusNotFound)
 		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
 		return
@@ -48,15 +41,7 @@ func PromoteToAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Promote the user to Admin
-	_, err = db.Exec("UPDATE users SET user_type = 'admin', updated_at = NOW() WHERE id = $1", userID)
-	if err != nil {
-		// http.Error(w, "Failed to promote user to Admin", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to promote user to Admin")
-		return
-	}
-
-	// Update user_roles table to reflect the new role
+	// Find the Admin role
 	var adminRoleID string
 	err = db.QueryRow("SELECT id FROM roles WHERE name = 'admin'").Scan(&adminRoleID)
 	if err == sql.ErrNoRows {
@@ -69,10 +54,11 @@ func PromoteToAdmin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = db.Exec("UPDATE user_roles SET role_id = $1 WHERE user_id = $2", adminRoleID, userID)
+	// Promote the user to Admin, roles outside the promotion ladder are kept
+	err = services.SetUserBuiltinRole(db, userID, adminRoleID, middleware.GetUserID(r))
 	if err != nil {
-		// http.Error(w, "Failed to update user role", http.StatusInternalServerError)
-		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update user role")
+		// http.Error(w, "Failed to promote user to Admin", http.StatusInternalServerError)
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to promote user to Admin")
 		return
 	}
 
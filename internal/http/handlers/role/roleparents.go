@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// GetRolePermissionTree shows the permissions of a role, those it grants directly and those it inherits from each parent role
+func GetRolePermissionTree(w http.ResponseWriter, r *http.Request) {
+	// Get the role_id from the URL path
+	roleID := mux.Vars(r)["role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	resolved, err := services.ResolveRolePermissions(db, roleID)
+	if err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to resolve role permissions")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Role permission tree retrieved successfully", resolved)
+}
+
+// AddRoleParent lets a role inherit every permission of a parent role.
+// Callers can only add parents whose permissions they hold themselves.
+func AddRoleParent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roleID, parentRoleID := vars["role_id"], vars["parent_role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Check both roles
+	roleName, err := services.GetRoleName(db, roleID)
+	if err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return
+	}
+	parentName, err := services.GetRoleName(db, parentRoleID)
+	if err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Parent role not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return
+	}
+
+	// Step 2: Inheriting is granting, the caller must hold what the parent grants
+	parentPermissions, err := services.ListEffectiveRolePermissions(db, parentRoleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role permissions")
+		return
+	}
+	callerPermissions, err := middleware.FetchRequestPermissions(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return
+	}
+	for _, perm := range parentPermissions {
+		if !middleware.CheckPermission([]string{perm.Name}, callerPermissions) {
+			utils.ErrorResponse(w, http.StatusForbidden, "You cannot grant a permission you do not hold: "+perm.Name)
+			return
+		}
+	}
+
+	// Step 3: Add the parent unless it would close a cycle
+	added, err := services.AddRoleParent(db, roleID, parentRoleID)
+	if err == services.ErrRoleCycle {
+		utils.ErrorResponse(w, http.StatusConflict, "Role "+parentName+" already inherits from "+roleName+", a role cannot inherit from itself")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to add parent role")
+		return
+	}
+	if added {
+		log.Println("Role", roleName, "now inherits from", parentName, "by", middleware.GetUserID(r))
+	}
+
+	respondRolePermissionTree(w, roleID, "Parent role added successfully")
+}
+
+// RemoveRoleParent stops a role from inheriting the permissions of a parent role
+func RemoveRoleParent(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	roleID, parentRoleID := vars["role_id"], vars["parent_role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	roleName, err := services.GetRoleName(db, roleID)
+	if err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return
+	}
+
+	removed, err := services.RemoveRoleParent(db, roleID, parentRoleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to remove parent role")
+		return
+	}
+	if !removed {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role does not inherit from this role")
+		return
+	}
+
+	log.Println("Role", roleName, "no longer inherits from", parentRoleID, "by", middleware.GetUserID(r))
+	respondRolePermissionTree(w, roleID, "Parent role removed successfully")
+}
+
+// respondRolePermissionTree responds with the resolved permissions of a role
+func respondRolePermissionTree(w http.ResponseWriter, roleID, message string) {
+	resolved, err := services.ResolveRolePermissions(database.Connect(), roleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to resolve role permissions")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, message, resolved)
+}
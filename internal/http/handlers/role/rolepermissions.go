@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ListRolePermissions lists the permissions a role grants
+func ListRolePermissions(w http.ResponseWriter, r *http.Request) {
+	// Get the role_id from the URL path
+	roleID := mux.Vars(r)["role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	if _, err := services.GetRoleName(db, roleID); err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return
+	}
+
+	permissions, err := services.ListRolePermissions(db, roleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role permissions")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Role permissions retrieved successfully", permissions)
+}
+
+// GrantRolePermission grants a single permission to a role
+func GrantRolePermission(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	changeRolePermissions(w, r, vars["role_id"], []string{vars["permission_id"]}, true)
+}
+
+// RevokeRolePermission revokes a single permission from a role
+func RevokeRolePermission(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	changeRolePermissions(w, r, vars["role_id"], []string{vars["permission_id"]}, false)
+}
+
+// GrantRolePermissions grants several permissions to a role at once
+func GrantRolePermissions(w http.ResponseWriter, r *http.Request) {
+	var req models.RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+	changeRolePermissions(w, r, mux.Vars(r)["role_id"], req.PermissionIDs, true)
+}
+
+// RevokeRolePermissions revokes several permissions from a role at once
+func RevokeRolePermissions(w http.ResponseWriter, r *http.Request) {
+	var req models.RolePermissionsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request Payload")
+		return
+	}
+	changeRolePermissions(w, r, mux.Vars(r)["role_id"], req.PermissionIDs, false)
+}
+
+// changeRolePermissions grants or revokes permissions of a role and responds with the permissions the role has afterwards.
+// Nothing changes unless every permission exists and passes the checks.
+func changeRolePermissions(w http.ResponseWriter, r *http.Request, roleID string, permissionIDs []string, grant bool) {
+	// Validate required fields
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range permissionIDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "At least one permission ID is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Check the role, system_admin must keep every permission
+	roleName, err := services.GetRoleName(db, roleID)
+	if err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return
+	}
+	if !grant && roleName == services.SystemAdminRole {
+		utils.ErrorResponse(w, http.StatusForbidden, "Permissions cannot be revoked from the system_admin role")
+		return
+	}
+
+	// Step 2: Check the permissions exist
+	permissions, err := services.FindPermissions(db, ids)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return
+	}
+	found := make(map[string]bool)
+	for _, perm := range permissions {
+		found[perm.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			utils.ErrorResponse(w, http.StatusNotFound, "Permission not found: "+id)
+			return
+		}
+	}
+
+	// Step 3: Callers cannot hand out permissions they do not hold themselves
+	if grant {
+		callerPermissions, err := middleware.FetchRequestPermissions(r)
+		if err != nil {
+			utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+			return
+		}
+		for _, perm := range permissions {
+			if !middleware.CheckPermission([]string{perm.Name}, callerPermissions) {
+				utils.ErrorResponse(w, http.StatusForbidden, "You cannot grant a permission you do not hold: "+perm.Name)
+				return
+			}
+		}
+	}
+
+	// Step 4: Apply the change
+	action := "revoked from"
+	var changed int64
+	if grant {
+		action = "granted to"
+		changed, err = services.GrantRolePermissions(db, roleID, ids)
+	} else {
+		changed, err = services.RevokeRolePermissions(db, roleID, ids)
+	}
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to update role permissions")
+		return
+	}
+	log.Println(changed, "permissions", action, "role", roleName, "by", middleware.GetUserID(r))
+
+	rolePermissions, err := services.ListRolePermissions(db, roleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role permissions")
+		return
+	}
+
+	message := "Permissions revoked successfully"
+	if grant {
+		message = "Permissions granted successfully"
+	}
+	utils.SuccessResponse(w, http.StatusOK, message, rolePermissions)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ListMySessions lists the active sessions of the authenticated user
+func ListMySessions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	sessions, err := services.ListSessions(db, userID, middleware.GetSessionID(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch sessions")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeMySession revokes a single session of the authenticated user
+func RevokeMySession(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Get the session_id from the URL path
+	sessionID := mux.Vars(r)["session_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	revoked, err := services.RevokeSession(db, userID, sessionID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+	if !revoked {
+		utils.ErrorResponse(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Session revoked successfully", nil)
+}
+
+// RevokeAllMySessions logs the authenticated user out everywhere, including the current session
+func RevokeAllMySessions(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	revoked, err := services.RevokeAllSessions(db, userID, "")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "All sessions revoked successfully", map[string]int64{
+		"revoked": revoked,
+	})
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// canAccessUser checks that the caller is the user itself or holds allPermission (e.g. user:update:all).
+// The permission comes from the request, so a personal access token limited to the self permission stays limited
+// whatever the role of its owner. It writes the error response itself and reports whether to go on.
+func canAccessUser(w http.ResponseWriter, r *http.Request, userID, allPermission string) bool {
+	if currentUserID := middleware.GetUserID(r); currentUserID != "" && currentUserID == userID {
+		return true
+	}
+
+	allowed, err := middleware.HasPermission(r, allPermission)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return false
+	}
+	if !allowed {
+		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this user")
+		return false
+	}
+	return true
+}
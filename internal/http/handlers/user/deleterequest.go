@@ -1,25 +1,18 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
 	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
 func DeleteRequest(w http.ResponseWriter, r *http.Request) {
 	userID := middleware.GetUserID(r)
 	deleteID := mux.Vars(r)["user_id"]
-	userType := middleware.GetUserType(r)
-	fmt.Println(userType)
-	if userType == "system_admin" {
-		// http.Error(w, "System admin cant be deleted", http.StatusBadRequest)
-		utils.ErrorResponse(w, http.StatusBadRequest, "System admin cant be deleted")
-		return
-	}
 	if userID == "" || deleteID == "" {
 		// http.Error(w, "You cannot access this page!", http.StatusBadRequest)
 		utils.ErrorResponse(w, http.StatusBadRequest, "You cannot access this page!")
@@ -32,12 +25,24 @@ func DeleteRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `UPDATE users SET deletion_requested = true, updated_at = NOW() WHERE id = $1`
-
 	// Connect to the database
 	db := database.Connect()
 
-	_, err := db.Exec(query, userID)
+	// The roles decide, a system admin keeps the account whatever user_type says
+	primaryRole, err := services.GetUserPrimaryRole(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user role")
+		return
+	}
+	if primaryRole == services.SystemAdminRole {
+		// http.Error(w, "System admin cant be deleted", http.StatusBadRequest)
+		utils.ErrorResponse(w, http.StatusBadRequest, "System admin cant be deleted")
+		return
+	}
+
+	query := `UPDATE users SET deletion_requested = true, updated_at = NOW() WHERE id = $1`
+
+	_, err = db.Exec(query, userID)
 	if err != nil {
 		// http.Error(w, "Failed to execute query", http.StatusInternalServerError)
 		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to execute query")
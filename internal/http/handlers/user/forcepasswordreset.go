@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ForcePasswordReset marks a user as having to change their password.
+// Until they do, every authenticated request except PUT /me/password is refused.
+func ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["user_id"]
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	res, err := db.Exec("UPDATE users SET must_change_password = TRUE, updated_at = NOW() WHERE id = $1", userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to force password reset")
+		return
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	log.Println("Password reset of user", userID, "forced by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "User must change their password on next request", nil)
+}
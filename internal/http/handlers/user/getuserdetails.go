@@ -18,20 +18,11 @@ func GetUserDetails(w http.ResponseWriter, r *http.Request) {
 	fmt.Println("Extracted User Type:", userType)
 	fmt.Println("Extracted User ID:", userID)
 
-	//Allow only authenticated users
-	if userType == "" || userID == "" {
-		// http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		utils.ErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
-		return
-	}
-
 	// Get the requested user ID from the URL
 	requestedUserID := mux.Vars(r)["user_id"]
 
-	//Allow only the user themselves or Admin/SystemAdmin
-	if requestedUserID != userID && userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "No permission to access this resource", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "No permission to access this resource")
+	//Allow only the user themselves or callers holding user:read:all
+	if !canAccessUser(w, r, requestedUserID, "user:read:all") {
 		return
 	}
 
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ResetUserMFA removes the second factors (TOTP, recovery codes and passkeys) of a user who lost access to them
+func ResetUserMFA(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["user_id"]
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+	if !exists {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	removed, err := services.DisableMFA(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to reset two-factor authentication")
+		return
+	}
+	passkeys, err := services.DeleteAllWebAuthnCredentials(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to reset two-factor authentication")
+		return
+	}
+	if !removed && passkeys == 0 {
+		utils.ErrorResponse(w, http.StatusNotFound, "Two-factor authentication is not set up for this user")
+		return
+	}
+
+	log.Println("Two-factor authentication of user", userID, "reset by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "Two-factor authentication reset successfully", nil)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// RevokeUserSessions kills every session of a user, for example after an account compromise
+func RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["user_id"]
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	var exists bool
+	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", userID).Scan(&exists)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+	if !exists {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	revoked, err := services.RevokeAllSessions(db, userID, "")
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	log.Println("All sessions of user", userID, "revoked by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "All sessions of the user revoked successfully", map[string]int64{
+		"revoked": revoked,
+	})
+}
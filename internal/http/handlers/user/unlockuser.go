@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/lockout"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// UnlockUser clears the failed login counter of a user, lifting a lockout before it expires
+func UnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["user_id"]
+	if userID == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "User ID is required")
+		return
+	}
+
+	// Connect to the database
+	db := database.Connect()
+
+	var email string
+	err := db.QueryRow("SELECT email FROM users WHERE id = $1", userID).Scan(&email)
+	if err == sql.ErrNoRows {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	cleared, err := lockout.Get().UnlockAccount(email)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to unlock user")
+		return
+	}
+
+	log.Println("User", userID, "unlocked by", middleware.GetUserID(r))
+	utils.SuccessResponse(w, http.StatusOK, "User unlocked successfully", map[string]bool{
+		"had_failures": cleared,
+	})
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/gorilla/mux"
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
-	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
 	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
@@ -24,13 +23,9 @@ type UpdateUserResponse struct {
 
 func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userID := mux.Vars(r)["user_id"]
-	currentUserID := middleware.GetUserID(r)
-	userType := middleware.GetUserType(r)
 
-	// Only allow self-update or Admin/SystemAdmin
-	if userID != currentUserID && userType != "admin" && userType != "system_admin" {
-		// http.Error(w, "You are not authorized to update this user", http.StatusForbidden)
-		utils.ErrorResponse(w, http.StatusForbidden, "You are not authorized to update this user")
+	// Only allow self-update or callers holding user:update:all, a limited token only counts with that permission in it
+	if !canAccessUser(w, r, userID, "user:update:all") {
 		return
 	}
 
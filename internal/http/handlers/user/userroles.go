@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// ListUserRoles lists the roles of a user and the primary role derived from them
+func ListUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID := mux.Vars(r)["user_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	respondUserRoles(w, db, userID, "User roles retrieved successfully")
+}
+
+// AddUserRole assigns a role to a user next to the roles they already have.
+// Callers can only hand out roles whose permissions they hold themselves.
+func AddUserRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, roleID := vars["user_id"], vars["role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Check the caller, the user and the role
+	roleName, ok := checkRoleChange(w, r, db, userID, roleID)
+	if !ok {
+		return
+	}
+
+	// Step 2: Assign the role
+	added, err := services.AddUserRole(db, userID, roleID, middleware.GetUserID(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to assign role")
+		return
+	}
+	if !added {
+		respondUserRoles(w, db, userID, "User already has this role")
+		return
+	}
+
+	log.Println("Role", roleName, "assigned to user", userID, "by", middleware.GetUserID(r))
+	respondUserRoles(w, db, userID, "Role assigned successfully")
+}
+
+// RemoveUserRole takes a role away from a user. The last role of a user and the last system admin cannot be removed.
+func RemoveUserRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, roleID := vars["user_id"], vars["role_id"]
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: Check the caller, the user and the role
+	roleName, ok := checkRoleChange(w, r, db, userID, roleID)
+	if !ok {
+		return
+	}
+
+	// Step 2: Remove the role, someone must be left to administer the system
+	removed, err := services.RemoveUserRole(db, userID, roleID)
+	if err == services.ErrLastRole {
+		utils.ErrorResponse(w, http.StatusBadRequest, "A user must keep at least one role")
+		return
+	} else if err == services.ErrLastSystemAdmin {
+		utils.ErrorResponse(w, http.StatusForbidden, "The last System Admin cannot lose the role")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to remove role")
+		return
+	}
+	if !removed {
+		utils.ErrorResponse(w, http.StatusNotFound, "User does not have this role")
+		return
+	}
+
+	log.Println("Role", roleName, "removed from user", userID, "by", middleware.GetUserID(r))
+	respondUserRoles(w, db, userID, "Role removed successfully")
+}
+
+// checkRoleChange checks that a user may change the roles of another user and returns the name of the role.
+// It writes the error response itself and reports whether to go on.
+func checkRoleChange(w http.ResponseWriter, r *http.Request, db *sql.DB, userID, roleID string) (string, bool) {
+	// user_roles records who assigned a role, which must be a user
+	if middleware.GetUserID(r) == "" {
+		utils.ErrorResponse(w, http.StatusForbidden, "Only users can change roles")
+		return "", false
+	}
+
+	if _, err := services.GetUserPrimaryRole(db, userID); err == services.ErrUserNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return "", false
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return "", false
+	}
+
+	roleName, err := services.GetRoleName(db, roleID)
+	if err == services.ErrRoleNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "Role not found")
+		return "", false
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role")
+		return "", false
+	}
+
+	// Assigning or removing a role must not reach beyond the caller's own permissions
+	rolePermissions, err := services.ListEffectiveRolePermissions(db, roleID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch role permissions")
+		return "", false
+	}
+	callerPermissions, err := middleware.FetchRequestPermissions(r)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch permissions")
+		return "", false
+	}
+	for _, perm := range rolePermissions {
+		if !middleware.CheckPermission([]string{perm.Name}, callerPermissions) {
+			utils.ErrorResponse(w, http.StatusForbidden, "You cannot change a role with permissions you do not hold: "+perm.Name)
+			return "", false
+		}
+	}
+	return roleName, true
+}
+
+// respondUserRoles responds with the roles of a user
+func respondUserRoles(w http.ResponseWriter, db *sql.DB, userID, message string) {
+	primaryRole, err := services.GetUserPrimaryRole(db, userID)
+	if err == services.ErrUserNotFound {
+		utils.ErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user roles")
+		return
+	}
+	roles, err := services.ListUserRoles(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user roles")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, message, models.UserRoles{
+		UserID:      userID,
+		PrimaryRole: primaryRole,
+		Roles:       roles,
+	})
+}
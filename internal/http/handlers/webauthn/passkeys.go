@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/webauthn"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+type FinishRegistrationRequest struct {
+	Name       string                      `json:"name"`
+	Credential webauthn.CredentialResponse `json:"credential"`
+}
+
+// BeginPasskeyRegistration returns the options for navigator.credentials.create() for the current user
+func BeginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	userID := middleware.GetUserID(r)
+
+	// Connect to the database
+	db := database.Connect()
+
+	var username, email string
+	if err := db.QueryRow("SELECT username, email FROM users WHERE id = $1", userID).Scan(&username, &email); err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch user")
+		return
+	}
+
+	userHandle, err := webauthn.UserHandle(userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Invalid user ID")
+		return
+	}
+
+	// Exclude the authenticators the user already registered
+	existing, err := services.WebAuthnDescriptors(db, userID)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch passkeys")
+		return
+	}
+
+	challenge, err := services.CreateWebAuthnChallenge(db, userID, webauthn.CeremonyRegistration)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to create challenge")
+		return
+	}
+
+	options := webauthn.NewCreationOptions(challenge, webauthn.UserEntity{
+		ID:          userHandle,
+		Name:        email,
+		DisplayName: username,
+	}, existing)
+
+	utils.SuccessResponse(w, http.StatusOK, "Passkey registration started", options)
+}
+
+// FinishPasskeyRegistration verifies the new credential and stores it for the current user
+func FinishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	var req FinishRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = "Passkey"
+	}
+	if len(req.Name) > 100 {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Name must be at most 100 characters")
+		return
+	}
+	userID := middleware.GetUserID(r)
+
+	// Connect to the database
+	db := database.Connect()
+
+	// Step 1: The challenge must have been issued to this user and is burnt here
+	challenge, err := webauthn.Challenge(req.Credential)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid client data")
+		return
+	}
+	challengeUserID, err := services.ConsumeWebAuthnChallenge(db, challenge, webauthn.CeremonyRegistration)
+	if err == services.ErrChallengeNotFound || (err == nil && challengeUserID != userID) {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Invalid or expired challenge")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to check challenge")
+		return
+	}
+
+	// Step 2: Verify the attestation
+	credential, err := webauthn.VerifyRegistration(req.Credential, challenge)
+	if err != nil {
+		log.Println("Passkey registration failed for user:", userID, "Error:", err)
+		utils.ErrorResponse(w, http.StatusBadRequest, "Passkey verification failed")
+		return
+	}
+
+	// Step 3: Store the credential
+	id, err := services.SaveWebAuthnCredential(db, userID, req.Name, credential, req.Credential.Response.Transports)
+	if err == services.ErrCredentialExists {
+		utils.ErrorResponse(w, http.StatusConflict, "This passkey is already registered")
+		return
+	} else if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to save passkey")
+		return
+	}
+
+	log.Println("Passkey registered for user:", userID)
+	utils.SuccessResponse(w, http.StatusCreated, "Passkey registered successfully", map[string]string{
+		"id":   id,
+		"name": req.Name,
+	})
+}
+
+// ListMyPasskeys returns the passkeys of the current user
+func ListMyPasskeys(w http.ResponseWriter, r *http.Request) {
+	credentials, err := services.ListWebAuthnCredentials(database.Connect(), middleware.GetUserID(r))
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to fetch passkeys")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Passkeys fetched successfully", credentials)
+}
+
+// DeleteMyPasskey removes one passkey of the current user
+func DeleteMyPasskey(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["credential_id"]
+	if id == "" {
+		utils.ErrorResponse(w, http.StatusBadRequest, "Credential ID is required")
+		return
+	}
+
+	deleted, err := services.DeleteWebAuthnCredential(database.Connect(), middleware.GetUserID(r), id)
+	if err != nil {
+		utils.ErrorResponse(w, http.StatusInternalServerError, "Failed to delete passkey")
+		return
+	}
+	if !deleted {
+		utils.ErrorResponse(w, http.StatusNotFound, "Passkey not found")
+		return
+	}
+
+	utils.SuccessResponse(w, http.StatusOK, "Passkey deleted successfully", nil)
+}
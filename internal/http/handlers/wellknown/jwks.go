@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+)
+
+// JWKS publishes the public signing keys so other services can verify our tokens
+// without being able to mint them. Symmetric keys are never listed.
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	jwks := struct {
+		Keys []keys.JWK `json:"keys"`
+	}{Keys: []keys.JWK{}}
+
+	// Rotated keys stay listed until they stop verifying
+	for _, key := range keys.GetRing().Keys() {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	// The JWK Set format is fixed by RFC 7517, so it is not wrapped in the usual response envelope
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jwks)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+)
+
+// OpenIDConfiguration is the OpenID Connect discovery document
+type OpenIDConfiguration struct {
+	Issuer                                 string   `json:"issuer"`
+	AuthorizationEndpoint                  string   `json:"authorization_endpoint"`
+	TokenEndpoint                          string   `json:"token_endpoint"`
+	UserInfoEndpoint                       string   `json:"userinfo_endpoint"`
+	IntrospectionEndpoint                  string   `json:"introspection_endpoint"`
+	RevocationEndpoint                     string   `json:"revocation_endpoint"`
+	JWKSURI                                string   `json:"jwks_uri"`
+	ScopesSupported                        []string `json:"scopes_supported"`
+	ResponseTypesSupported                 []string `json:"response_types_supported"`
+	GrantTypesSupported                    []string `json:"grant_types_supported"`
+	SubjectTypesSupported                  []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported       []string `json:"id_token_signing_alg_values_supported"`
+	TokenEndpointAuthMethodsSupported      []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported          []string `json:"code_challenge_methods_supported"`
+	ClaimsSupported                        []string `json:"claims_supported"`
+	AuthorizationResponseIssParamSupported bool     `json:"authorization_response_iss_parameter_supported"`
+}
+
+// OpenIDDiscovery describes the OAuth2 / OpenID Connect provider so relying parties can configure themselves.
+// The endpoints are built from JWT_ISSUER, which relying parties compare against the iss claim of ID tokens.
+func OpenIDDiscovery(w http.ResponseWriter, r *http.Request) {
+	issuer := config.GetConfig().JWT.Issuer
+	base := strings.TrimSuffix(issuer, "/")
+
+	discovery := OpenIDConfiguration{
+		Issuer:                                 issuer,
+		AuthorizationEndpoint:                  base + "/oauth/authorize",
+		TokenEndpoint:                          base + "/oauth/token",
+		UserInfoEndpoint:                       base + "/oauth/userinfo",
+		IntrospectionEndpoint:                  base + "/oauth/introspect",
+		RevocationEndpoint:                     base + "/oauth/revoke",
+		JWKSURI:                                base + "/.well-known/jwks.json",
+		ScopesSupported:                        oidc.StandardScopes,
+		ResponseTypesSupported:                 []string{"code"},
+		GrantTypesSupported:                    []string{oidc.GrantAuthorizationCode, oidc.GrantClientCredentials},
+		SubjectTypesSupported:                  []string{"public"},
+		IDTokenSigningAlgValuesSupported:       []string{keys.SigningKey().Method().Alg()},
+		TokenEndpointAuthMethodsSupported:      []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:          []string{oidc.CodeChallengeMethodS256},
+		AuthorizationResponseIssParamSupported: true,
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "azp",
+			"name", "given_name", "family_name", "preferred_username", "updated_at",
+			"email", "email_verified", "roles", "permissions",
+		},
+	}
+
+	// The discovery format is fixed by OpenID Connect Discovery 1.0, so it is not wrapped in the usual response envelope
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(discovery)
+}
@@ -4,56 +4,166 @@ import (
 	"context"
 	// "fmt"
 	"net/http"
+	"strings"
 
-	"github.com/golang-jwt/jwt/v5"
-	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/tokens"
+	"github.com/sagorsarker04/Developer-Assignment/internal/services"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
 )
 
+// passwordChangePath is the only endpoint open to users who must change their password
+const passwordChangePath = "/api/v1/me/password"
+
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get the token from the cookie
-		cookie, err := r.Cookie("auth_token")
+		// Get the token from the Authorization header or the cookie
+		tokenString, err := ExtractToken(r)
 		if err != nil {
 			http.Error(w, "No valid authentication token", http.StatusUnauthorized)
 			return
 		}
 
-		// Load the config
-		// cfg, err := config.LoadConfig()
-		// if err != nil {
-		// 	http.Error(w, "Failed to load config", http.StatusInternalServerError)
-		// 	return
-		// }
-		//new singletone class
-		cfg:=config.GetConfig()
-
-		// Parse the token
-		tokenString := cookie.Value
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return []byte(cfg.JWT.Secret), nil
-		})
+		// Personal access tokens are opaque and looked up in the database
+		if strings.HasPrefix(tokenString, services.PersonalAccessTokenPrefix) {
+			authenticatePersonalAccessToken(w, r, next, tokenString)
+			return
+		}
 
-		if err != nil || !token.Valid {
+		// Parse the token, only access tokens are accepted here
+		claims, err := tokens.Parse(tokens.PurposeAccess, tokenString)
+		if err != nil {
 			http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 			return
 		}
 
-		// Extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			userID, _ := claims["user_id"].(string)
-			username, _ := claims["username"].(string)
-			userType, _ := claims["user_type"].(string)
+		// Tokens revoked at /oauth/revoke keep a valid signature until they expire
+		revoked, err := services.IsTokenRevoked(database.Connect(), claims.ID)
+		if err != nil {
+			http.Error(w, "Failed to check token", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+			return
+		}
 
-			// Set values in the context
-			ctx := context.WithValue(r.Context(), UserIDKey, userID)
-			ctx = context.WithValue(ctx, UsernameKey, username)
-			ctx = context.WithValue(ctx, UserTypeKey, userType)
-			//fmt.Println(ctx)
+		// Service accounts have no session, their tokens live as long as the client keeps the client_credentials grant
+		if claims.UserID == "" && claims.ClientID != "" {
+			active, err := services.IsServiceAccountActive(database.Connect(), claims.ClientID)
+			if err != nil {
+				http.Error(w, "Failed to check service account", http.StatusInternalServerError)
+				return
+			}
+			if !active {
+				http.Error(w, "Service account has been removed", http.StatusUnauthorized)
+				return
+			}
 
-			// Pass the request to the next handler
+			ctx := context.WithValue(r.Context(), ClientIDKey, claims.ClientID)
+			ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+			ctx = context.WithValue(ctx, UserTypeKey, claims.UserType)
+			if claims.Scope != "" {
+				ctx = context.WithValue(ctx, ScopeKey, claims.Scope)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
-		} else {
+			return
+		}
+
+		// The session behind the token must still be active on the server
+		if claims.UserID == "" || claims.SessionID == "" {
 			http.Error(w, "Invalid token claims", http.StatusUnauthorized)
+			return
+		}
+		active, err := services.IsSessionActive(database.Connect(), claims.SessionID, claims.UserID)
+		if err != nil {
+			http.Error(w, "Failed to check session", http.StatusInternalServerError)
+			return
 		}
+		if !active {
+			http.Error(w, "Session has been revoked", http.StatusUnauthorized)
+			return
+		}
+
+		if !checkPasswordChange(w, r, claims.UserID) {
+			return
+		}
+
+		// Set values in the context
+		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+		ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+		ctx = context.WithValue(ctx, UserTypeKey, claims.UserType)
+		ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+		//fmt.Println(ctx)
+
+		// Pass the request to the next handler
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireSession only lets through users signed in with a session, it runs after AuthMiddleware.
+// Account security routes (passkeys, two-factor authentication, sessions, personal access tokens) use it,
+// so a personal access token or service account can never turn its limited access into a full login.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if GetUserID(r) == "" || GetSessionID(r) == "" {
+			http.Error(w, "This endpoint requires a signed in session", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
 	})
 }
+
+// authenticatePersonalAccessToken serves a request made with a personal access token.
+// The token acts as its user, but RequireAnyPermission only grants it the permissions it was created with.
+func authenticatePersonalAccessToken(w http.ResponseWriter, r *http.Request, next http.Handler, tokenString string) {
+	db := database.Connect()
+
+	pat, err := services.AuthenticatePersonalAccessToken(db, tokenString, utils.ClientIP(r))
+	if err == services.ErrInvalidPersonalAccessToken {
+		http.Error(w, "Invalid, expired or revoked personal access token", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to check personal access token", http.StatusInternalServerError)
+		return
+	}
+
+	user, err := services.GetUser(db, pat.UserID)
+	if err == services.ErrUserNotFound || (err == nil && !user.Active) {
+		http.Error(w, "Invalid, expired or revoked personal access token", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		http.Error(w, "Failed to fetch user", http.StatusInternalServerError)
+		return
+	}
+
+	if !checkPasswordChange(w, r, user.ID) {
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), UserIDKey, user.ID)
+	ctx = context.WithValue(ctx, UsernameKey, user.Username)
+	ctx = context.WithValue(ctx, UserTypeKey, user.UserType)
+	// Always limited, a token without permissions must not fall back to all permissions of its user
+	ctx = context.WithValue(ctx, ScopeKey, strings.Join(pat.Permissions, " "))
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// checkPasswordChange blocks users an admin forced to reset their password from everything but the password change.
+// It writes the error response itself and reports whether to go on.
+func checkPasswordChange(w http.ResponseWriter, r *http.Request, userID string) bool {
+	if r.URL.Path == passwordChangePath && r.Method == http.MethodPut {
+		return true
+	}
+
+	mustChange, err := services.MustChangePassword(database.Connect(), userID)
+	if err != nil {
+		http.Error(w, "Failed to check account status", http.StatusInternalServerError)
+		return false
+	}
+	if mustChange {
+		http.Error(w, "Password change required, use PUT "+passwordChangePath, http.StatusForbidden)
+		return false
+	}
+	return true
+}
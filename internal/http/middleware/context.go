@@ -8,9 +8,15 @@ import (
 type UserContextKeys string
 
 const (
-	UserIDKey   UserContextKeys = "user_id"
-	UsernameKey UserContextKeys = "username"
-	UserTypeKey UserContextKeys = "user_type"
+	UserIDKey    UserContextKeys = "user_id"
+	UsernameKey  UserContextKeys = "username"
+	UserTypeKey  UserContextKeys = "user_type"
+	SessionIDKey UserContextKeys = "session_id"
+	// ClientIDKey is set instead of UserIDKey when a service account calls with a client_credentials token
+	ClientIDKey UserContextKeys = "client_id"
+	// ScopeKey holds the scope a token is limited to. It is only set for limited tokens,
+	// a limited token with an empty scope carries no permissions at all.
+	ScopeKey UserContextKeys = "scope"
 )
 
 // GetUserID extracts the user ID from the request context
@@ -36,3 +42,25 @@ func GetUserType(r *http.Request) string {
 	}
 	return ""
 }
+
+// GetSessionID extracts the session ID from the request context
+func GetSessionID(r *http.Request) string {
+	if sessionID, ok := r.Context().Value(SessionIDKey).(string); ok {
+		return sessionID
+	}
+	return ""
+}
+
+// GetClientID extracts the service account client ID from the request context
+func GetClientID(r *http.Request) string {
+	if clientID, ok := r.Context().Value(ClientIDKey).(string); ok {
+		return clientID
+	}
+	return ""
+}
+
+// GetScope extracts the scope the token of the request is limited to, and whether it is limited at all
+func GetScope(r *http.Request) (string, bool) {
+	scope, ok := r.Context().Value(ScopeKey).(string)
+	return scope, ok
+}
@@ -4,20 +4,37 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 
 	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/matcher"
 )
 
+// routePermissions holds every permission passed to RequireAnyPermission while the routes are set up
+var (
+	routePermissionsMu sync.RWMutex
+	routePermissions   = make(map[string]bool)
+)
+
+// IsRoutePermission reports whether a route requires the permission, renaming or deleting it would lock the route
+func IsRoutePermission(name string) bool {
+	routePermissionsMu.RLock()
+	defer routePermissionsMu.RUnlock()
+	return routePermissions[name]
+}
+
 // FetchAllUserPermissions returns all permissions of a given user
 func FetchAllUserPermissions(userID string) ([]string, error) {
 	
 // Connect to the database
 db:=database.Connect()
 
+	// effective_role_permissions includes the permissions roles inherit from their parents
 	query := `
-		SELECT p.name
+		SELECT DISTINCT p.name
 		FROM user_roles ur
-		JOIN role_permissions rp ON ur.role_id = rp.role_id
+		JOIN effective_role_permissions rp ON ur.role_id = rp.role_id
 		JOIN permissions p ON rp.permission_id = p.id
 		WHERE ur.user_id = $1
 	`
@@ -44,29 +61,108 @@ db:=database.Connect()
 	return permissions, nil
 }
 
+// FetchAllClientPermissions returns all permissions of a service account, granted through its roles like those of a user
+func FetchAllClientPermissions(clientID string) ([]string, error) {
+	db := database.Connect()
 
-// CheckPermission checks if any required permission is in the available permissions list
+	rows, err := db.Query(`
+		SELECT DISTINCT p.name
+		FROM oauth_client_roles cr
+		JOIN effective_role_permissions rp ON cr.role_id = rp.role_id
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE cr.client_id = $1`,
+		clientID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []string
+	for rows.Next() {
+		var perm string
+		if err := rows.Scan(&perm); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, perm)
+	}
+	return permissions, rows.Err()
+}
+
+// LimitToScope narrows the permissions to a space separated scope.
+// Each permission is intersected with each scope entry, so wildcards on either side keep only what both cover.
+func LimitToScope(permissions []string, scope string) []string {
+	var limited []string
+	seen := make(map[string]bool)
+	for _, perm := range permissions {
+		for _, s := range strings.Fields(scope) {
+			narrowed, ok := matcher.Intersect(perm, s)
+			if ok && !seen[narrowed] {
+				seen[narrowed] = true
+				limited = append(limited, narrowed)
+			}
+		}
+	}
+	return limited
+}
+
+// CheckPermission checks if any required permission is covered by the available permissions,
+// wildcards and broader scopes included (see the matcher package)
 func CheckPermission(required []string, available []string) bool {
 	for _, reqPerm := range required {
-		for _, availPerm := range available {
-			if reqPerm == availPerm {
-				return true
-			}
+		if matcher.CoversAny(available, reqPerm) {
+			return true
 		}
 	}
 	return false
 }
 
-// RequireAnyPermission checks if the user has at least one of the required permissions
+// FetchRequestPermissions returns the permissions the caller of an authenticated request holds.
+// Those of a user or service account, narrowed to the scope when the token is limited to one.
+func FetchRequestPermissions(r *http.Request) ([]string, error) {
+	var permissions []string
+	var err error
+	if userID := GetUserID(r); userID != "" {
+		permissions, err = FetchAllUserPermissions(userID)
+	} else if clientID := GetClientID(r); clientID != "" {
+		permissions, err = FetchAllClientPermissions(clientID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// A token limited to a scope only carries the permissions in it
+	if scope, limited := GetScope(r); limited {
+		permissions = LimitToScope(permissions, scope)
+	}
+	return permissions, nil
+}
+
+// HasPermission reports whether the caller of an authenticated request holds a permission.
+// Handlers use it to decide beyond the route's own check, e.g. whether a caller may act on other users.
+func HasPermission(r *http.Request, permission string) (bool, error) {
+	permissions, err := FetchRequestPermissions(r)
+	if err != nil {
+		return false, err
+	}
+	return CheckPermission([]string{permission}, permissions), nil
+}
+
+// RequireAnyPermission checks if the user or service account has at least one of the required permissions
 func RequireAnyPermission(required []string, next http.Handler) http.Handler {
+	routePermissionsMu.Lock()
+	for _, perm := range required {
+		routePermissions[perm] = true
+	}
+	routePermissionsMu.Unlock()
+
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userID := GetUserID(r)
-		if userID == "" {
+		if GetUserID(r) == "" && GetClientID(r) == "" {
 			http.Error(w, "No Valid user", http.StatusForbidden)
 			return
 		}
+		availablePermissions, err := FetchRequestPermissions(r)
 
-		availablePermissions, err := FetchAllUserPermissions(userID)
 		if err != nil || len(availablePermissions) == 0 {
 			http.Error(w, "No valiable permissions", http.StatusForbidden)
 			return
@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestLimitToScope(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		scope       string
+		want        []string
+	}{
+		{"exact", []string{"role:read", "user:read:all"}, "role:read", []string{"role:read"}},
+		{"wildcard holder, literal scope", []string{"user:*"}, "user:read:all user:demote", []string{"user:read:all", "user:demote"}},
+		{"literal holder, wildcard scope", []string{"user:read:all", "role:read"}, "user:*", []string{"user:read:all"}},
+		{"crossing wildcards", []string{"*:read"}, "user:*", []string{"user:read"}},
+		{"broader scope narrowed", []string{"user:update:all"}, "user:update:self", []string{"user:update:self"}},
+		{"narrower holder stays narrow", []string{"user:update:self"}, "user:update:all", []string{"user:update:self"}},
+		{"duplicates removed", []string{"*", "user:*"}, "user:demote", []string{"user:demote"}},
+		{"nothing in common", []string{"role:read"}, "key:manage", nil},
+		{"empty scope grants nothing", []string{"*"}, "", nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := LimitToScope(tc.permissions, tc.scope); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("LimitToScope(%v, %q) = %v, want %v", tc.permissions, tc.scope, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetScope(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, limited := GetScope(r); limited {
+		t.Fatal("expected a request without a scope not to be limited")
+	}
+	r = r.WithContext(context.WithValue(r.Context(), ScopeKey, ""))
+	if scope, limited := GetScope(r); !limited || scope != "" {
+		t.Fatalf("GetScope = %q, %v, want an empty limited scope", scope, limited)
+	}
+}
+
+func TestCheckPermission(t *testing.T) {
+	available := []string{"user:*", "role:read"}
+	if !CheckPermission([]string{"permission:read", "user:update:self"}, available) {
+		t.Fatal("expected any covered requirement to pass")
+	}
+	if CheckPermission([]string{"permission:read", "key:manage"}, available) {
+		t.Fatal("expected uncovered requirements to fail")
+	}
+}
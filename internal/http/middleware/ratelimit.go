@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/ratelimit"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// maxRateLimitBody bounds how much of the body is buffered to find the email key
+const maxRateLimitBody = 1 << 20
+
+// RateLimit limits the requests to a route with one token bucket per client key.
+// Every key of the rule (ip, email, user) has its own bucket and the request must pass all of them,
+// tokens are only taken when it does.
+// The user key needs AuthMiddleware to run first. If the store fails the request is let through.
+func RateLimit(name string, rule config.RateLimitRule) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if rule.Requests == 0 {
+			return next
+		}
+		limit := ratelimit.FromRule(rule)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var keys []string
+			for _, kind := range rule.Keys {
+				if value := rateLimitKey(r, kind); value != "" {
+					keys = append(keys, name+":"+kind+":"+value)
+				}
+			}
+			if len(keys) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// A request rejected by one bucket must not use up the tokens of the others
+			allowed, wait, err := ratelimit.Get().Take(keys, limit)
+			if err != nil {
+				log.Println("Rate limit check failed for", name, "Error:", err)
+			} else if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				utils.ErrorResponse(w, http.StatusTooManyRequests, "Too many requests, try again later")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey returns the value identifying the client for a key kind, empty when it is not available
+func rateLimitKey(r *http.Request, kind string) string {
+	switch kind {
+	case "ip":
+		return utils.ClientIP(r)
+	case "user":
+		return GetUserID(r)
+	case "email":
+		return emailFromBody(r)
+	}
+	return ""
+}
+
+// emailFromBody reads the email field of a JSON body and puts the body back for the handler
+func emailFromBody(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRateLimitBody))
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(payload.Email))
+}
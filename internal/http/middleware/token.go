@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+// ErrNoToken is returned when the request carries no access token in any configured source
+var ErrNoToken = errors.New("no authentication token")
+
+// ExtractToken returns the access token of the request.
+// Sources are tried in the order configured by TOKEN_LOOKUP: the "Authorization: Bearer" header and/or the auth cookie.
+func ExtractToken(r *http.Request) (string, error) {
+	cfg := config.GetConfig()
+
+	for _, source := range cfg.JWT.TokenLookup {
+		switch source {
+		case "header":
+			if token := bearerToken(r); token != "" {
+				return token, nil
+			}
+		case "cookie":
+			if cookie, err := r.Cookie(cfg.Cookie.Name); err == nil && cookie.Value != "" {
+				return cookie.Value, nil
+			}
+		}
+	}
+	return "", ErrNoToken
+}
+
+// bearerToken reads the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
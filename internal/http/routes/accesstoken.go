@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/accesstoken"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterAccessTokenRoutes(router *mux.Router) {
+	// Current User Personal Access Token Routes
+	tokens := api.PathPrefix("/me/tokens").Subrouter()
+	tokens.Use(middleware.AuthMiddleware, middleware.RequireSession)
+	tokens.HandleFunc("", handlers.ListMyAccessTokens).Methods(http.MethodGet)                // Authenticated
+	tokens.HandleFunc("", handlers.CreateMyAccessToken).Methods(http.MethodPost)              // Authenticated
+	tokens.HandleFunc("/{token_id}", handlers.RevokeMyAccessToken).Methods(http.MethodDelete) // Authenticated
+}
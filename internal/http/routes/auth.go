@@ -4,22 +4,36 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
 	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/auth"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
 )
 
 // RegisterAuthRoutes registers the authentication-related routes.
 func RegisterRoutes(router *mux.Router) {
 
+	cfg := config.GetConfig()
+
 	// Authentication Routes
 	auth := api.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/login", handlers.LoginUser).Methods(http.MethodPost)
 	auth.HandleFunc("/logout", handlers.LogoutUser).Methods(http.MethodPost)
-	auth.HandleFunc("/register", handlers.RegisterUser).Methods(http.MethodPost)
+	auth.HandleFunc("/refresh", handlers.RefreshToken).Methods(http.MethodPost)
+	auth.HandleFunc("/mfa/verify", handlers.VerifyMFALogin).Methods(http.MethodPost)
+	auth.HandleFunc("/webauthn/login/begin", handlers.BeginPasskeyLogin).Methods(http.MethodPost)
+	auth.HandleFunc("/webauthn/login/finish", handlers.FinishPasskeyLogin).Methods(http.MethodPost)
+	auth.Handle("/register", middleware.RateLimit("register", cfg.RateLimit.Register)(http.HandlerFunc(handlers.RegisterUser))).Methods(http.MethodPost)
 	auth.HandleFunc("/verify/{token}", handlers.VerifyEmail).Methods(http.MethodGet)
-	auth.HandleFunc("/resend-verification", handlers.ResendVerificationEmail).Methods(http.MethodPost)
-	auth.HandleFunc("/password-reset-request", handlers.PasswordResetRequest).Methods(http.MethodPost)
+	auth.Handle("/resend-verification", middleware.RateLimit("resend-verification", cfg.RateLimit.ResendVerification)(http.HandlerFunc(handlers.ResendVerificationEmail))).Methods(http.MethodPost)
+	auth.Handle("/password-reset-request", middleware.RateLimit("password-reset-request", cfg.RateLimit.PasswordResetRequest)(http.HandlerFunc(handlers.PasswordResetRequest))).Methods(http.MethodPost)
 	auth.HandleFunc("/password-reset-confirm", handlers.PasswordResetConfirm).Methods(http.MethodPost)
 	auth.HandleFunc("/password-reset", handlers.PasswordReset).Methods(http.MethodPost)
 
+	// Passwordless login by email, only on deployments that enable it
+	if cfg.MagicLink.Enabled {
+		auth.Handle("/magic-link/request", middleware.RateLimit("magic-link-request", cfg.RateLimit.MagicLinkRequest)(http.HandlerFunc(handlers.RequestMagicLink))).Methods(http.MethodPost)
+		auth.HandleFunc("/magic-link/consume", handlers.ConsumeMagicLink).Methods(http.MethodPost)
+	}
+
 
 }
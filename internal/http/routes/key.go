@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/key"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterKeyRoutes(router *mux.Router) {
+	// Signing Key Routes
+	signingKeys := api.PathPrefix("/keys").Subrouter()
+	signingKeys.Use(middleware.AuthMiddleware)
+
+	signingKeys.Handle("", middleware.RequireAnyPermission([]string{"key:manage"}, http.HandlerFunc(handlers.ListSigningKeys))).Methods(http.MethodGet)
+
+	signingKeys.Handle("/rotate", middleware.RequireAnyPermission([]string{"key:manage"}, http.HandlerFunc(handlers.RotateSigningKey))).Methods(http.MethodPost)
+
+	signingKeys.Handle("/{kid}/retire", middleware.RequireAnyPermission([]string{"key:manage"}, http.HandlerFunc(handlers.RetireSigningKey))).Methods(http.MethodPost)
+}
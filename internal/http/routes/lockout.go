@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/lockout"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterLockoutRoutes(router *mux.Router) {
+	// Login Lockout Routes
+	lockouts := api.PathPrefix("/lockouts").Subrouter()
+	lockouts.Use(middleware.AuthMiddleware)
+	lockouts.Handle("", middleware.RequireAnyPermission([]string{"lockout:manage"}, http.HandlerFunc(handlers.ListLockouts))).Methods(http.MethodGet)
+	lockouts.Handle("/ip/{ip}", middleware.RequireAnyPermission([]string{"lockout:manage"}, http.HandlerFunc(handlers.UnlockIP))).Methods(http.MethodDelete)
+}
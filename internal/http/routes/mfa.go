@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/mfa"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterMFARoutes(router *mux.Router) {
+	// Current User Two-Factor Authentication Routes
+	mfa := api.PathPrefix("/me/mfa").Subrouter()
+	mfa.Use(middleware.AuthMiddleware, middleware.RequireSession)
+	mfa.HandleFunc("", handlers.GetMyMFAStatus).Methods(http.MethodGet)                          // Authenticated
+	mfa.HandleFunc("", handlers.DisableMyMFA).Methods(http.MethodDelete)                         // Authenticated
+	mfa.HandleFunc("/totp", handlers.EnrollTOTP).Methods(http.MethodPost)                        // Authenticated
+	mfa.HandleFunc("/totp/confirm", handlers.ConfirmTOTP).Methods(http.MethodPost)               // Authenticated
+	mfa.HandleFunc("/recovery-codes", handlers.RegenerateRecoveryCodes).Methods(http.MethodPost) // Authenticated
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/oauth"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterOAuthRoutes(router *mux.Router) {
+	// OAuth2 / OpenID Connect provider endpoints, at the server root next to the discovery document
+	oauth := router.PathPrefix("/oauth").Subrouter()
+	oauth.Handle("/authorize", middleware.AuthMiddleware(http.HandlerFunc(handlers.Authorize))).Methods(http.MethodGet, http.MethodPost) // Authenticated
+	oauth.HandleFunc("/token", handlers.Token).Methods(http.MethodPost)
+	oauth.HandleFunc("/userinfo", handlers.UserInfo).Methods(http.MethodGet, http.MethodPost) // OAuth access token
+	oauth.HandleFunc("/introspect", handlers.Introspect).Methods(http.MethodPost)             // Client credentials
+	oauth.HandleFunc("/revoke", handlers.Revoke).Methods(http.MethodPost)                     // Client credentials
+
+	// OAuth Client Routes
+	clients := api.PathPrefix("/oauth/clients").Subrouter()
+	clients.Use(middleware.AuthMiddleware)
+
+	clients.Handle("", middleware.RequireAnyPermission([]string{"oauth:client:manage"}, http.HandlerFunc(handlers.ListOAuthClients))).Methods(http.MethodGet)
+
+	clients.Handle("", middleware.RequireAnyPermission([]string{"oauth:client:manage"}, http.HandlerFunc(handlers.CreateOAuthClient))).Methods(http.MethodPost)
+
+	clients.Handle("/{client_id}", middleware.RequireAnyPermission([]string{"oauth:client:manage"}, http.HandlerFunc(handlers.DeleteOAuthClient))).Methods(http.MethodDelete)
+
+	clients.Handle("/{client_id}/roles/{role_id}", middleware.RequireAnyPermission([]string{"oauth:client:manage"}, http.HandlerFunc(handlers.AssignOAuthClientRole))).Methods(http.MethodPost)
+
+	clients.Handle("/{client_id}/roles/{role_id}", middleware.RequireAnyPermission([]string{"oauth:client:manage"}, http.HandlerFunc(handlers.RemoveOAuthClientRole))).Methods(http.MethodDelete)
+}
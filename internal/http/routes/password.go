@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/password"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterPasswordRoutes(router *mux.Router) {
+	// Current User Password Routes
+	password := api.PathPrefix("/me/password").Subrouter()
+	password.Use(middleware.AuthMiddleware)
+	password.HandleFunc("", handlers.ChangeMyPassword).Methods(http.MethodPut) // Authenticated, also allowed while a password change is required
+}
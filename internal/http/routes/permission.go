@@ -12,10 +12,16 @@ func RegisterPermissionRoutes(router *mux.Router) {
 	// Permission Routes
 	permissions := api.PathPrefix("/permissions").Subrouter()
 	permissions.Use(middleware.AuthMiddleware)
-	permissions.HandleFunc("/create", handlers.CreatePermission).Methods(http.MethodPost)
+	permissions.Handle("/create", middleware.RequireAnyPermission([]string{"permission:create"}, http.HandlerFunc(handlers.CreatePermission))).Methods(http.MethodPost)
 	
 	permissions.Handle("", middleware.RequireAnyPermission([]string{"permission:read"}, http.HandlerFunc(handlers.ListAllPermissions))).Methods(http.MethodGet)                  // Admin+
-	permissions.HandleFunc("/{permission_id}", handlers.GetPermissionDetails).Methods(http.MethodGet) // Admin+
+	permissions.Handle("/usage", middleware.RequireAnyPermission([]string{"permission:read"}, http.HandlerFunc(handlers.ListPermissionUsage))).Methods(http.MethodGet)
+	permissions.Handle("/match", middleware.RequireAnyPermission([]string{"permission:read"}, http.HandlerFunc(handlers.MatchPermission))).Methods(http.MethodPost)
+	permissions.Handle("/{permission_id}", middleware.RequireAnyPermission([]string{"permission:read"}, http.HandlerFunc(handlers.GetPermissionDetails))).Methods(http.MethodGet)
+
+	permissions.Handle("/{permission_id}", middleware.RequireAnyPermission([]string{"permission:update"}, http.HandlerFunc(handlers.UpdatePermission))).Methods(http.MethodPut)
+
+	permissions.Handle("/{permission_id}", middleware.RequireAnyPermission([]string{"permission:delete"}, http.HandlerFunc(handlers.DeletePermission))).Methods(http.MethodDelete)
 
 	// Current User Routes
 	me := api.PathPrefix("/me").Subrouter()
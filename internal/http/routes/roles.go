@@ -24,6 +24,22 @@ func RoleRoutes(router *mux.Router) {
 	roles.Handle("/{role_id}", middleware.RequireAnyPermission([]string{"role:update"}, http.HandlerFunc(handlers.UpdateRole))).Methods(http.MethodPut)
 
 	roles.Handle("/{role_id}", middleware.RequireAnyPermission([]string{"role:delete"}, http.HandlerFunc(handlers.DeleteRole))).Methods(http.MethodDelete)
+
+	roles.Handle("/{role_id}/permissions", middleware.RequireAnyPermission([]string{"role:read"}, http.HandlerFunc(handlers.ListRolePermissions))).Methods(http.MethodGet)
+
+	roles.Handle("/{role_id}/permissions/tree", middleware.RequireAnyPermission([]string{"role:read"}, http.HandlerFunc(handlers.GetRolePermissionTree))).Methods(http.MethodGet)
+
+	roles.Handle("/{role_id}/permissions", middleware.RequireAnyPermission([]string{"role:permission:manage"}, http.HandlerFunc(handlers.GrantRolePermissions))).Methods(http.MethodPost)
+
+	roles.Handle("/{role_id}/permissions", middleware.RequireAnyPermission([]string{"role:permission:manage"}, http.HandlerFunc(handlers.RevokeRolePermissions))).Methods(http.MethodDelete)
+
+	roles.Handle("/{role_id}/permissions/{permission_id}", middleware.RequireAnyPermission([]string{"role:permission:manage"}, http.HandlerFunc(handlers.GrantRolePermission))).Methods(http.MethodPost)
+
+	roles.Handle("/{role_id}/permissions/{permission_id}", middleware.RequireAnyPermission([]string{"role:permission:manage"}, http.HandlerFunc(handlers.RevokeRolePermission))).Methods(http.MethodDelete)
+
+	roles.Handle("/{role_id}/parents/{parent_role_id}", middleware.RequireAnyPermission([]string{"role:permission:manage"}, http.HandlerFunc(handlers.AddRoleParent))).Methods(http.MethodPost)
+
+	roles.Handle("/{role_id}/parents/{parent_role_id}", middleware.RequireAnyPermission([]string{"role:permission:manage"}, http.HandlerFunc(handlers.RemoveRoleParent))).Methods(http.MethodDelete)
 	
 	roles.Handle("/{user_id}/role", middleware.RequireAnyPermission([]string{"role:update","user:update:all"}, http.HandlerFunc(handlers.ChangeUserRole))).Methods(http.MethodPost)
 
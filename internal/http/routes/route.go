@@ -10,4 +10,13 @@ func SetupRoutes(router *mux.Router) {
 	RoleRoutes(router)
 	RegisterPermissionRoutes(router)
 	RegisterUserRoutes(router)
+	RegisterSessionRoutes(router)
+	RegisterAccessTokenRoutes(router)
+	RegisterPasswordRoutes(router)
+	RegisterMFARoutes(router)
+	RegisterWebAuthnRoutes(router)
+	RegisterLockoutRoutes(router)
+	RegisterKeyRoutes(router)
+	RegisterOAuthRoutes(router)
+	RegisterWellKnownRoutes(router)
 }
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/session"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterSessionRoutes(router *mux.Router) {
+	// Current User Session Routes
+	sessions := api.PathPrefix("/me/sessions").Subrouter()
+	sessions.Use(middleware.AuthMiddleware, middleware.RequireSession)
+	sessions.HandleFunc("", handlers.ListMySessions).Methods(http.MethodGet)                  // Authenticated
+	sessions.HandleFunc("", handlers.RevokeAllMySessions).Methods(http.MethodDelete)          // Authenticated
+	sessions.HandleFunc("/{session_id}", handlers.RevokeMySession).Methods(http.MethodDelete) // Authenticated
+}
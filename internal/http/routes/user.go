@@ -24,5 +24,19 @@ func RegisterUserRoutes(r *mux.Router) {
 
 	users.Handle("/{user_id}", middleware.RequireAnyPermission([]string{"user:delete:all"}, http.HandlerFunc(handlers.DeleteUser))).Methods(http.MethodDelete)
 
+	users.Handle("/{user_id}/roles", middleware.RequireAnyPermission([]string{"user:read:all"}, http.HandlerFunc(handlers.ListUserRoles))).Methods(http.MethodGet)
+
+	users.Handle("/{user_id}/roles/{role_id}", middleware.RequireAnyPermission([]string{"user:role:manage"}, http.HandlerFunc(handlers.AddUserRole))).Methods(http.MethodPost)
+
+	users.Handle("/{user_id}/roles/{role_id}", middleware.RequireAnyPermission([]string{"user:role:manage"}, http.HandlerFunc(handlers.RemoveUserRole))).Methods(http.MethodDelete)
+
+	users.Handle("/{user_id}/sessions", middleware.RequireAnyPermission([]string{"session:revoke:all"}, http.HandlerFunc(handlers.RevokeUserSessions))).Methods(http.MethodDelete)
+
+	users.Handle("/{user_id}/mfa", middleware.RequireAnyPermission([]string{"mfa:reset"}, http.HandlerFunc(handlers.ResetUserMFA))).Methods(http.MethodDelete)
+
+	users.Handle("/{user_id}/force-password-reset", middleware.RequireAnyPermission([]string{"password:force_reset"}, http.HandlerFunc(handlers.ForcePasswordReset))).Methods(http.MethodPost)
+
+	users.Handle("/{user_id}/lockout", middleware.RequireAnyPermission([]string{"lockout:manage"}, http.HandlerFunc(handlers.UnlockUser))).Methods(http.MethodDelete)
+
 	// users.HandleFunc("/{user_id}/demote", handlers.DemoteUserRole).Methods(http.MethodPost) // Admin+
 }
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/webauthn"
+	"github.com/sagorsarker04/Developer-Assignment/internal/http/middleware"
+)
+
+func RegisterWebAuthnRoutes(router *mux.Router) {
+	// Passkey Registration Routes, next to the passkey login routes in /auth/webauthn
+	register := api.PathPrefix("/auth/webauthn/register").Subrouter()
+	register.Use(middleware.AuthMiddleware, middleware.RequireSession)
+	register.HandleFunc("/begin", handlers.BeginPasskeyRegistration).Methods(http.MethodPost)   // Authenticated
+	register.HandleFunc("/finish", handlers.FinishPasskeyRegistration).Methods(http.MethodPost) // Authenticated
+
+	// Current User Passkey Routes
+	passkeys := api.PathPrefix("/me/webauthn/credentials").Subrouter()
+	passkeys.Use(middleware.AuthMiddleware, middleware.RequireSession)
+	passkeys.HandleFunc("", handlers.ListMyPasskeys).Methods(http.MethodGet)                     // Authenticated
+	passkeys.HandleFunc("/{credential_id}", handlers.DeleteMyPasskey).Methods(http.MethodDelete) // Authenticated
+}
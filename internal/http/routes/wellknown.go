@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	handlers "github.com/sagorsarker04/Developer-Assignment/internal/http/handlers/wellknown"
+)
+
+// RegisterWellKnownRoutes registers the public discovery endpoints at the server root
+func RegisterWellKnownRoutes(router *mux.Router) {
+	wellKnown := router.PathPrefix("/.well-known").Subrouter()
+	wellKnown.HandleFunc("/jwks.json", handlers.JWKS).Methods(http.MethodGet)
+	wellKnown.HandleFunc("/openid-configuration", handlers.OpenIDDiscovery).Methods(http.MethodGet)
+}
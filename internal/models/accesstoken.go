@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// PersonalAccessToken is a long-lived token a user creates for scripts, limited to some of the user's permissions.
+// The token itself is only shown once, Prefix is enough to recognise it afterwards.
+type PersonalAccessToken struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Permissions []string   `json:"permissions"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	LastUsedIP  string     `json:"last_used_ip,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// PersonalAccessTokenRequest represents the JSON payload for creating a personal access token
+type PersonalAccessTokenRequest struct {
+	Name          string   `json:"name"`
+	Permissions   []string `json:"permissions"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// NewPersonalAccessToken is returned once when a token is created
+type NewPersonalAccessToken struct {
+	PersonalAccessToken
+	Token string `json:"token"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Lockout is an account or client IP that is currently blocked from logging in
+type Lockout struct {
+	Type              string `json:"type"`
+	Subject           string `json:"subject"`
+	Failures          int    `json:"failures"`
+	RetryAfterSeconds int    `json:"retry_after_seconds"`
+}
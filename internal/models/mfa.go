@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// MFAStatus describes the second factor of a user
+type MFAStatus struct {
+	Enabled                bool       `json:"enabled"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	Passkeys               int        `json:"passkeys"`
+}
+
+// MFAEnrollment is returned when a user starts TOTP enrollment
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
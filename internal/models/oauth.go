@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OAuthClient is an application registered with the OAuth2 / OpenID Connect provider.
+// Clients allowed the client_credentials grant are service accounts: they act on their own behalf with the permissions of their roles.
+type OAuthClient struct {
+	ClientID      string    `json:"client_id"`
+	Name          string    `json:"name"`
+	RedirectURIs  []string  `json:"redirect_uris"`
+	AllowedScopes []string  `json:"allowed_scopes"`
+	GrantTypes    []string  `json:"grant_types"`
+	Roles         []string  `json:"roles"`
+	Public        bool      `json:"public"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// OAuthClientRequest represents the JSON payload for registering an OAuth client.
+// Public clients (SPAs, mobile apps) get no secret and rely on PKCE alone.
+type OAuthClientRequest struct {
+	Name          string   `json:"name"`
+	RedirectURIs  []string `json:"redirect_uris"`
+	AllowedScopes []string `json:"allowed_scopes"`
+	GrantTypes    []string `json:"grant_types"`
+	Public        bool     `json:"public"`
+}
+
+// OAuthClientCredentials is returned once when a client is registered, the secret cannot be read again
+type OAuthClientCredentials struct {
+	OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
@@ -0,0 +1,55 @@
+package models
+
+// Permission is an action on a resource that roles grant, e.g. user:read:all
+type Permission struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Resource    string `json:"resource"`
+	Action      string `json:"action"`
+}
+
+// RolePermissionsRequest represents the JSON payload for granting or revoking several permissions of a role
+type RolePermissionsRequest struct {
+	PermissionIDs []string `json:"permission_ids"`
+}
+
+// RoleSummary identifies a role in listings
+type RoleSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// PermissionUsage shows where a permission is used, so unused ones can be cleaned up safely
+type PermissionUsage struct {
+	Permission
+	RequiredByRoutes bool          `json:"required_by_routes"`
+	Roles            []RoleSummary `json:"roles"`
+	UserCount        int           `json:"user_count"`
+}
+
+// RolePermissionTree is a role with the permissions granted to it directly and the trees of its parent roles
+type RolePermissionTree struct {
+	RoleSummary
+	Permissions []string             `json:"permissions"`
+	Parents     []RolePermissionTree `json:"parents"`
+}
+
+// ResolvedRolePermissions shows where the permissions of a role come from and what they add up to
+type ResolvedRolePermissions struct {
+	Tree                 RolePermissionTree `json:"tree"`
+	EffectivePermissions []string           `json:"effective_permissions"`
+}
+
+// PermissionMatchRequest represents the JSON payload for testing whether a grant covers a required permission
+type PermissionMatchRequest struct {
+	Grant    string `json:"grant"`
+	Required string `json:"required"`
+}
+
+// PermissionMatch is the outcome of testing a grant against a required permission
+type PermissionMatch struct {
+	Grant    string `json:"grant"`
+	Required string `json:"required"`
+	Covers   bool   `json:"covers"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// UserRoles lists the roles of a user and the primary role derived from them
+type UserRoles struct {
+	UserID      string        `json:"user_id"`
+	PrimaryRole string        `json:"primary_role"`
+	Roles       []RoleSummary `json:"roles"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// WebAuthnCredential is a registered passkey or security key, the public key is never exposed
+type WebAuthnCredential struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name"`
+	CredentialID   string     `json:"credential_id"`
+	Transports     []string   `json:"transports"`
+	BackupEligible bool       `json:"backup_eligible"`
+	CreatedAt      time.Time  `json:"created_at"`
+	LastUsedAt     *time.Time `json:"last_used_at"`
+}
@@ -0,0 +1,137 @@
+package breach
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// bloomMagic starts every filter file, followed by the header fields and the bit array, little endian
+var bloomMagic = [8]byte{'P', 'W', 'B', 'L', 'O', 'O', 'M', '1'}
+
+const (
+	headerSize = 32
+	chunkSize  = 1 << 20
+)
+
+var ErrBadBloomFile = errors.New("breach: not a password Bloom filter file")
+
+// Bloom is a Bloom filter of the SHA-1 hashes of breached passwords.
+// It cannot tell exact counts, it only holds hashes seen at least MinCount times.
+type Bloom struct {
+	MinCount int    // occurrence threshold the filter was built with
+	Entries  uint64 // number of hashes added
+	k        uint32
+	m        uint64
+	bits     []uint64
+}
+
+// NewBloom sizes a filter for n hashes with the given false positive rate
+func NewBloom(n uint64, fpRate float64, minCount int) *Bloom {
+	if n == 0 {
+		n = 1
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2)))
+	k := uint32(math.Max(1, math.Round(float64(m)/float64(n)*math.Ln2)))
+	m = (m + 63) / 64 * 64
+
+	return &Bloom{MinCount: minCount, k: k, m: m, bits: make([]uint64, m/64)}
+}
+
+// OpenBloom reads a filter written by Bloom.WriteTo
+func OpenBloom(path string) (*Bloom, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	var header struct {
+		Magic    [8]byte
+		K        uint32
+		MinCount uint32
+		M        uint64
+		Entries  uint64
+	}
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, ErrBadBloomFile
+	}
+	if header.Magic != bloomMagic || header.K == 0 || header.M == 0 || header.M%64 != 0 {
+		return nil, ErrBadBloomFile
+	}
+
+	b := &Bloom{MinCount: int(header.MinCount), Entries: header.Entries, k: header.K, m: header.M}
+	b.bits = make([]uint64, header.M/64)
+
+	// Read the bit array in chunks, filters of the full corpus take gigabytes
+	buf := make([]byte, chunkSize)
+	for i := 0; i < len(b.bits); {
+		n := min(len(buf), (len(b.bits)-i)*8)
+		if _, err := io.ReadFull(r, buf[:n]); err != nil {
+			return nil, fmt.Errorf("breach: truncated Bloom filter: %w", err)
+		}
+		for j := 0; j < n; j, i = j+8, i+1 {
+			b.bits[i] = binary.LittleEndian.Uint64(buf[j:])
+		}
+	}
+	return b, nil
+}
+
+// AddHash adds a hex encoded SHA-1 hash
+func (b *Bloom) AddHash(hexHash string) error {
+	digest, err := hex.DecodeString(hexHash)
+	if err != nil || len(digest) != sha1.Size {
+		return fmt.Errorf("breach: %q is not a SHA-1 hash", hexHash)
+	}
+	for i := uint32(0); i < b.k; i++ {
+		idx := b.index(digest, i)
+		b.bits[idx/64] |= 1 << (idx % 64)
+	}
+	b.Entries++
+	return nil
+}
+
+// Count returns MinCount when the password is probably in the filter, 0 when it certainly is not
+func (b *Bloom) Count(password string) (int, error) {
+	digest := sha1.Sum([]byte(password))
+	for i := uint32(0); i < b.k; i++ {
+		idx := b.index(digest[:], i)
+		if b.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return 0, nil
+		}
+	}
+	return b.MinCount, nil
+}
+
+// index derives the i-th bit position from the digest by double hashing, SHA-1 output is already uniform
+func (b *Bloom) index(digest []byte, i uint32) uint64 {
+	h1 := binary.LittleEndian.Uint64(digest[0:8])
+	h2 := binary.LittleEndian.Uint64(digest[8:16]) | 1
+	return (h1 + uint64(i)*h2) % b.m
+}
+
+// WriteTo writes the filter in the format OpenBloom reads
+func (b *Bloom) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriterSize(w, chunkSize)
+	for _, field := range []interface{}{bloomMagic, b.k, uint32(b.MinCount), b.m, b.Entries} {
+		if err := binary.Write(bw, binary.LittleEndian, field); err != nil {
+			return 0, err
+		}
+	}
+
+	var word [8]byte
+	for _, bits := range b.bits {
+		binary.LittleEndian.PutUint64(word[:], bits)
+		if _, err := bw.Write(word[:]); err != nil {
+			return 0, err
+		}
+	}
+	return int64(headerSize + len(b.bits)*8), bw.Flush()
+}
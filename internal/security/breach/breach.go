@@ -0,0 +1,37 @@
+package breach
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Sources a breach corpus can be loaded from
+const (
+	SourceHIBP  = "hibp"
+	SourceBloom = "bloom"
+)
+
+// Checker tells how often a password appears in a breach corpus
+type Checker interface {
+	// Count returns the number of times the password was seen, 0 when it was not
+	Count(password string) (int, error)
+}
+
+// Open loads a breach corpus, path is a directory of HIBP range files or a Bloom filter file
+func Open(source, path string) (Checker, error) {
+	switch source {
+	case SourceHIBP:
+		return OpenRangeDir(path)
+	case SourceBloom:
+		return OpenBloom(path)
+	}
+	return nil, fmt.Errorf("breach: unknown source %q", source)
+}
+
+// Hash returns the upper case hex SHA-1 of a password, the form HIBP lists passwords in
+func Hash(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
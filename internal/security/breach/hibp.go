@@ -0,0 +1,130 @@
+package breach
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// prefixLength is the length of the hash prefix HIBP range files are split by
+const prefixLength = 5
+
+// RangeDir reads a local copy of the HIBP range API: one file per hash prefix, named
+// after the prefix (00000.txt ... FFFFF.txt), holding "SUFFIX:COUNT" lines for the hashes under it.
+type RangeDir struct {
+	dir string
+}
+
+// OpenRangeDir checks that dir looks like a HIBP range download
+func OpenRangeDir(dir string) (*RangeDir, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breach: %s is not a directory of HIBP range files", dir)
+	}
+	return &RangeDir{dir: dir}, nil
+}
+
+func (r *RangeDir) Count(password string) (int, error) {
+	hash := Hash(password)
+	return r.lookup(hash[:prefixLength], hash[prefixLength:])
+}
+
+// lookup scans the range file of prefix for suffix, a missing range file counts as not found
+func (r *RangeDir) lookup(prefix, suffix string) (int, error) {
+	file, err := openRange(r.dir, prefix)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash, count, ok := parseRangeLine(scanner.Text())
+		if ok && strings.EqualFold(hash, suffix) {
+			return count, nil
+		}
+	}
+	return 0, scanner.Err()
+}
+
+// openRange opens the range file of a prefix, with or without the .txt extension
+func openRange(dir, prefix string) (*os.File, error) {
+	file, err := os.Open(filepath.Join(dir, prefix+".txt"))
+	if errors.Is(err, os.ErrNotExist) {
+		file, err = os.Open(filepath.Join(dir, prefix))
+	}
+	return file, err
+}
+
+// parseRangeLine splits a "HASH:COUNT" line. Padding entries with a count of 0 are skipped.
+func parseRangeLine(line string) (string, int, bool) {
+	hash, countStr, ok := strings.Cut(strings.TrimSpace(line), ":")
+	if !ok {
+		return "", 0, false
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return "", 0, false
+	}
+	return hash, count, true
+}
+
+// Walk calls fn with the full SHA-1 hash and count of every entry in a HIBP corpus.
+// path is either a range directory or a single file of "HASH:COUNT" lines, like the full HIBP download.
+func Walk(path string, fn func(hash string, count int) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return walkFile(path, "", fn)
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		prefix := strings.TrimSuffix(entry.Name(), ".txt")
+		if entry.IsDir() || len(prefix) != prefixLength {
+			continue
+		}
+		if err := walkFile(filepath.Join(path, entry.Name()), strings.ToUpper(prefix), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkFile(path, prefix string, fn func(hash string, count int) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		hash, count, ok := parseRangeLine(scanner.Text())
+		if !ok {
+			continue
+		}
+		hash = prefix + strings.ToUpper(hash)
+		if len(hash) != 40 {
+			return fmt.Errorf("breach: %s: %q is not a SHA-1 hash", path, hash)
+		}
+		if err := fn(hash, count); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,65 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+)
+
+// GenerateKey creates a fresh key for the algorithm.
+// It also returns the private material in the form it is stored in the signing_keys table:
+// base64 for HS256 secrets and PKCS #8 PEM for the asymmetric algorithms.
+func GenerateKey(algorithm string) (*Key, string, error) {
+	if algorithm == AlgHS256 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, "", err
+		}
+		material := base64.StdEncoding.EncodeToString(secret)
+		key, err := NewHMACKey("", secret)
+		return key, material, err
+	}
+
+	var private interface{}
+	var err error
+	switch algorithm {
+	case AlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	case AlgES256:
+		private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, "", fmt.Errorf("unsupported algorithm %q", algorithm)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, "", err
+	}
+	material := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	key, err := ParsePrivateKey("", algorithm, []byte(material))
+	return key, material, err
+}
+
+// keyFromMaterial rebuilds a key stored by GenerateKey
+func keyFromMaterial(kid, algorithm, material string) (*Key, error) {
+	if algorithm == AlgHS256 {
+		secret, err := base64.StdEncoding.DecodeString(material)
+		if err != nil {
+			return nil, err
+		}
+		return NewHMACKey(kid, secret)
+	}
+	return ParsePrivateKey(kid, algorithm, []byte(material))
+}
@@ -0,0 +1,117 @@
+package keys
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+)
+
+// reloadInterval is how often the ring picks up rotations made by other instances
+const reloadInterval = time.Minute
+
+// minReloadInterval throttles reloads triggered by tokens with an unknown kid
+const minReloadInterval = 10 * time.Second
+
+var (
+	ring       *Ring
+	configured *Key
+	once       sync.Once
+	reloadMu   sync.Mutex
+)
+
+// LoadKey builds the signing key described by the JWT configuration.
+// HS256 uses JWT_SECRET, the asymmetric algorithms read the PEM file at JWT_PRIVATE_KEY_PATH.
+func LoadKey(cfg config.JWTConfig) (*Key, error) {
+	if cfg.Algorithm == AlgHS256 {
+		return NewHMACKey(cfg.KeyID, []byte(cfg.Secret))
+	}
+
+	if cfg.PrivateKeyPath == "" {
+		return nil, fmt.Errorf("JWT_PRIVATE_KEY_PATH is required for %s", cfg.Algorithm)
+	}
+	pemBytes, err := os.ReadFile(cfg.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	return ParsePrivateKey(cfg.KeyID, cfg.Algorithm, pemBytes)
+}
+
+// GetRing returns the keyring singleton, loading it on first use
+func GetRing() *Ring {
+	once.Do(func() {
+		var err error
+		configured, err = LoadKey(config.GetConfig().JWT)
+		if err != nil {
+			log.Fatalf("failed to load JWT signing key: %v", err)
+		}
+
+		ring = &Ring{}
+		if err := Reload(); err != nil {
+			log.Fatalf("failed to load signing keys: %v", err)
+		}
+	})
+
+	if ring.age() > reloadInterval {
+		if err := Reload(); err != nil {
+			log.Println("Failed to reload signing keys:", err)
+		}
+	}
+	return ring
+}
+
+// Reload refreshes the ring from the signing_keys table
+func Reload() error {
+	if ring == nil {
+		// First use, GetRing performs the initial load
+		GetRing()
+		return nil
+	}
+
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	active, keys, err := loadRing(database.Connect(), configured)
+	if err != nil {
+		return err
+	}
+	ring.replace(active, keys)
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func SigningKey() *Key {
+	return GetRing().SigningKey()
+}
+
+// Keyfunc resolves the verification key for a token by its kid header.
+// The token's alg must match the algorithm of the key, which rules out algorithm confusion attacks.
+func Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing key id")
+	}
+
+	keyRing := GetRing()
+	key, ok := keyRing.Lookup(kid)
+	if !ok && keyRing.age() > minReloadInterval {
+		// The key may have been rotated in by another instance
+		if err := Reload(); err != nil {
+			return nil, err
+		}
+		key, ok = keyRing.Lookup(kid)
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %s", kid)
+	}
+
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.VerifyKey(), nil
+}
@@ -0,0 +1,59 @@
+package keys
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Ring holds every key that may verify tokens and the single key that signs new ones
+type Ring struct {
+	mu       sync.RWMutex
+	active   *Key
+	keys     map[string]*Key
+	loadedAt time.Time
+}
+
+// SigningKey returns the active signing key
+func (r *Ring) SigningKey() *Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active
+}
+
+// Lookup returns the verification key with the given kid
+func (r *Ring) Lookup(kid string) (*Key, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.keys[kid]
+	return key, ok
+}
+
+// Keys returns every verification key, ordered by kid
+func (r *Ring) Keys() []*Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(r.keys))
+	for _, key := range r.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+// age reports how long ago the ring was loaded
+func (r *Ring) age() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return time.Since(r.loadedAt)
+}
+
+// replace swaps the ring contents after a reload
+func (r *Ring) replace(active *Key, keys map[string]*Key) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.active = active
+	r.keys = keys
+	r.loadedAt = time.Now()
+}
@@ -0,0 +1,254 @@
+package keys
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+// Key statuses stored in the signing_keys table
+const (
+	StatusActive    = "active"
+	StatusVerifying = "verifying"
+	StatusRetired   = "retired"
+)
+
+var (
+	// ErrKeyNotFound is returned when no key has the requested kid
+	ErrKeyNotFound = errors.New("signing key not found")
+	// ErrActiveKey is returned when trying to retire the key that currently signs tokens
+	ErrActiveKey = errors.New("the active signing key cannot be retired, rotate first")
+)
+
+// KeyInfo describes a stored key without its private material
+type KeyInfo struct {
+	ID          string     `json:"kid"`
+	Algorithm   string     `json:"alg"`
+	Status      string     `json:"status"`
+	Configured  bool       `json:"configured"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RotatedAt   *time.Time `json:"rotated_at"`
+	VerifyUntil *time.Time `json:"verify_until"`
+}
+
+// loadRing reads the usable keys from the database.
+// The key configured through JWT_SECRET / JWT_PRIVATE_KEY_PATH is registered on first use and has no stored material.
+func loadRing(db *sql.DB, configured *Key) (*Key, map[string]*Key, error) {
+	_, err := db.Exec(`
+		INSERT INTO signing_keys (kid, algorithm, private_key, status, created_at)
+		VALUES ($1, $2, NULL, CASE WHEN EXISTS (SELECT 1 FROM signing_keys WHERE status = 'active') THEN 'verifying' ELSE 'active' END, NOW())
+		ON CONFLICT (kid) DO NOTHING`,
+		configured.ID, configured.Algorithm,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := db.Query(`
+		SELECT kid, algorithm, private_key, status
+		FROM signing_keys
+		WHERE status <> 'retired' AND (verify_until IS NULL OR verify_until > NOW())`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var active *Key
+	keys := map[string]*Key{}
+	for rows.Next() {
+		var kid, algorithm, status string
+		var material sql.NullString
+		if err := rows.Scan(&kid, &algorithm, &material, &status); err != nil {
+			return nil, nil, err
+		}
+
+		var key *Key
+		if !material.Valid {
+			// A configured key, only usable while it is still the one in the environment
+			if kid != configured.ID {
+				continue
+			}
+			key = configured
+		} else {
+			key, err = keyFromMaterial(kid, algorithm, material.String)
+			if err != nil {
+				log.Printf("Skipping unreadable signing key %s: %v", kid, err)
+				continue
+			}
+		}
+
+		keys[kid] = key
+		if status == StatusActive {
+			active = key
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// Never end up without a signing key
+	if active == nil {
+		active = configured
+		keys[configured.ID] = configured
+	}
+	return active, keys, nil
+}
+
+// Rotate generates a new active key with the configured algorithm.
+// The previous active key keeps verifying for JWT_KEY_RETENTION so issued tokens stay valid until they expire.
+// With olderThan > 0 the rotation only happens when the active key is at least that old.
+func Rotate(db *sql.DB, olderThan time.Duration) (*KeyInfo, error) {
+	cfg := config.GetConfig()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Serialize rotations across instances
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext('signing_keys'))`); err != nil {
+		return nil, err
+	}
+
+	if olderThan > 0 {
+		var due bool
+		err := tx.QueryRow(`
+			SELECT COALESCE(bool_and(created_at <= NOW() - make_interval(secs => $1)), TRUE)
+			FROM signing_keys WHERE status = 'active'`,
+			olderThan.Seconds(),
+		).Scan(&due)
+		if err != nil {
+			return nil, err
+		}
+		if !due {
+			return nil, nil
+		}
+	}
+
+	key, material, err := GenerateKey(cfg.JWT.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE signing_keys
+		SET status = 'verifying', rotated_at = NOW(), verify_until = NOW() + make_interval(secs => $1)
+		WHERE status = 'active'`,
+		retention(cfg).Seconds(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	info := KeyInfo{ID: key.ID, Algorithm: key.Algorithm, Status: StatusActive}
+	err = tx.QueryRow(`
+		INSERT INTO signing_keys (kid, algorithm, private_key, status, created_at)
+		VALUES ($1, $2, $3, 'active', NOW())
+		RETURNING created_at`,
+		key.ID, key.Algorithm, material,
+	).Scan(&info.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Signing key rotated, new active key: %s", key.ID)
+	return &info, Reload()
+}
+
+// Retire stops a key from verifying tokens immediately, e.g. after it leaked.
+func Retire(db *sql.DB, kid string) error {
+	var status string
+	err := db.QueryRow(`SELECT status FROM signing_keys WHERE kid = $1`, kid).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrKeyNotFound
+	} else if err != nil {
+		return err
+	}
+	if status == StatusActive {
+		return ErrActiveKey
+	}
+
+	_, err = db.Exec(`
+		UPDATE signing_keys SET status = 'retired', verify_until = LEAST(COALESCE(verify_until, NOW()), NOW())
+		WHERE kid = $1`,
+		kid,
+	)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Signing key retired: %s", kid)
+	return Reload()
+}
+
+// List returns every stored key, newest first
+func List(db *sql.DB) ([]KeyInfo, error) {
+	rows, err := db.Query(`
+		SELECT kid, algorithm, status, private_key IS NULL, created_at, rotated_at, verify_until
+		FROM signing_keys
+		ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []KeyInfo{}
+	for rows.Next() {
+		var info KeyInfo
+		var rotatedAt, verifyUntil sql.NullTime
+		if err := rows.Scan(&info.ID, &info.Algorithm, &info.Status, &info.Configured, &info.CreatedAt, &rotatedAt, &verifyUntil); err != nil {
+			return nil, err
+		}
+		if rotatedAt.Valid {
+			info.RotatedAt = &rotatedAt.Time
+		}
+		if verifyUntil.Valid {
+			info.VerifyUntil = &verifyUntil.Time
+		}
+		keys = append(keys, info)
+	}
+	return keys, rows.Err()
+}
+
+// StartRotationSchedule rotates the active key every JWT_KEY_ROTATION_INTERVAL.
+// It is a no-op when the interval is zero.
+func StartRotationSchedule(db *sql.DB) {
+	interval := config.GetConfig().JWT.KeyRotationInterval
+	if interval <= 0 {
+		return
+	}
+
+	check := interval / 10
+	if check > time.Hour {
+		check = time.Hour
+	}
+
+	go func() {
+		for {
+			if _, err := Rotate(db, interval); err != nil {
+				log.Println("Scheduled signing key rotation failed:", err)
+			}
+			time.Sleep(check)
+		}
+	}()
+}
+
+// retention is how long a rotated key keeps verifying.
+// It is never shorter than the longest lived token signed with it.
+func retention(cfg *config.Config) time.Duration {
+	retention := cfg.JWT.KeyRetention
+	for _, ttl := range []time.Duration{cfg.JWT.Expiry, cfg.Email.VerificationTTL, cfg.Password.PasswordResetTTL} {
+		if ttl > retention {
+			retention = ttl
+		}
+	}
+	return retention
+}
@@ -0,0 +1,174 @@
+package lockout
+
+import (
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+)
+
+// pruneInterval is how often stale counters are dropped
+const pruneInterval = 10 * time.Minute
+
+// Key prefixes, an account is keyed by its normalized email so unknown emails are throttled too
+const (
+	accountPrefix = "account:"
+	ipPrefix      = "ip:"
+)
+
+// Guard applies the lockout policy on top of a Store
+type Guard struct {
+	store  Store
+	policy config.LockoutConfig
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+var (
+	guard *Guard
+	once  sync.Once
+)
+
+// Get returns the guard singleton, using the store selected by LOCKOUT_STORE
+func Get() *Guard {
+	once.Do(func() {
+		cfg := config.GetConfig()
+
+		var store Store
+		switch cfg.Lockout.Store {
+		case "memory":
+			store = NewMemoryStore()
+		default:
+			store = NewPostgresStore(database.Connect())
+		}
+		guard = NewGuard(store, cfg.Lockout)
+	})
+	return guard
+}
+
+// NewGuard returns a guard enforcing the policy with the given store
+func NewGuard(store Store, policy config.LockoutConfig) *Guard {
+	return &Guard{store: store, policy: policy}
+}
+
+// AccountKey returns the counter key of an email address
+func AccountKey(email string) string {
+	return accountPrefix + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey returns the counter key of a client IP
+func IPKey(ip string) string {
+	return ipPrefix + ip
+}
+
+// Check returns how long the caller has to wait before the next attempt, the longest block of all keys
+func (g *Guard) Check(keys ...string) (time.Duration, error) {
+	g.prune()
+
+	var wait time.Duration
+	for _, key := range keys {
+		entry, err := g.store.Status(key)
+		if err != nil {
+			return 0, err
+		}
+		if entry.RetryAfter > wait {
+			wait = entry.RetryAfter
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed attempt for an account and the client IP and blocks them as the policy says
+func (g *Guard) Fail(accountKey, ipKey string) error {
+	if err := g.fail(accountKey, g.policy.MaxAttempts); err != nil {
+		return err
+	}
+	return g.fail(ipKey, g.policy.IPMaxAttempts)
+}
+
+// Succeed clears the counter of an account after a successful login.
+// The IP counter is kept, otherwise logging into an own account would reset it.
+func (g *Guard) Succeed(accountKey string) error {
+	_, err := g.store.Reset(accountKey)
+	return err
+}
+
+// UnlockAccount clears the counter of an email address
+func (g *Guard) UnlockAccount(email string) (bool, error) {
+	return g.store.Reset(AccountKey(email))
+}
+
+// UnlockIP clears the counter of a client IP
+func (g *Guard) UnlockIP(ip string) (bool, error) {
+	return g.store.Reset(IPKey(ip))
+}
+
+// Blocked lists the accounts and IPs that are currently blocked
+func (g *Guard) Blocked() ([]Entry, error) {
+	return g.store.Blocked()
+}
+
+func (g *Guard) fail(key string, maxAttempts int) error {
+	failures, err := g.store.Fail(key, g.policy.Window)
+	if err != nil {
+		return err
+	}
+	if d := g.delay(failures, maxAttempts); d > 0 {
+		if maxAttempts > 0 && failures >= maxAttempts {
+			log.Println("Locked out", key, "after", failures, "failed attempts for", d)
+		}
+		return g.store.Block(key, d)
+	}
+	return nil
+}
+
+// delay is the block after the given number of consecutive failures.
+// Below maxAttempts it doubles with every failure starting at BackoffBase, capped at BackoffMax.
+// From maxAttempts on the key is locked for Duration. A maxAttempts of zero disables the lockout.
+func (g *Guard) delay(failures, maxAttempts int) time.Duration {
+	if maxAttempts <= 0 {
+		return 0
+	}
+	if failures >= maxAttempts {
+		return g.policy.Duration
+	}
+
+	d := g.policy.BackoffBase
+	for i := 1; i < failures && d < g.policy.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > g.policy.BackoffMax {
+		d = g.policy.BackoffMax
+	}
+	return d
+}
+
+// prune drops stale counters, at most once per pruneInterval
+func (g *Guard) prune() {
+	g.mu.Lock()
+	if time.Since(g.lastPrune) < pruneInterval {
+		g.mu.Unlock()
+		return
+	}
+	g.lastPrune = time.Now()
+	g.mu.Unlock()
+
+	if err := g.store.Prune(g.policy.Window); err != nil {
+		log.Println("Failed to prune login attempts:", err)
+	}
+}
+
+// Subject splits a key into its kind ("account" or "ip") and value
+func Subject(key string) (string, string) {
+	switch {
+	case strings.HasPrefix(key, accountPrefix):
+		return "account", strings.TrimPrefix(key, accountPrefix)
+	case strings.HasPrefix(key, ipPrefix):
+		return "ip", strings.TrimPrefix(key, ipPrefix)
+	}
+	return "", key
+}
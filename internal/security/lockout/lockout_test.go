@@ -0,0 +1,117 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+var testPolicy = config.LockoutConfig{
+	MaxAttempts:   5,
+	IPMaxAttempts: 20,
+	Duration:      15 * time.Minute,
+	BackoffBase:   time.Second,
+	BackoffMax:    5 * time.Second,
+	Window:        time.Hour,
+}
+
+func TestDelay(t *testing.T) {
+	tests := []struct {
+		name        string
+		failures    int
+		maxAttempts int
+		want        time.Duration
+	}{
+		{"first failure waits the base", 1, 5, time.Second},
+		{"second failure doubles", 2, 5, 2 * time.Second},
+		{"third failure doubles again", 3, 5, 4 * time.Second},
+		{"capped at the maximum", 4, 5, 5 * time.Second},
+		{"capped below a high limit", 15, 20, 5 * time.Second},
+		{"locked at max attempts", 5, 5, 15 * time.Minute},
+		{"locked past max attempts", 9, 5, 15 * time.Minute},
+		{"zero max attempts disables", 3, 0, 0},
+		{"zero max attempts never locks", 100, 0, 0},
+	}
+	g := NewGuard(NewMemoryStore(), testPolicy)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := g.delay(tc.failures, tc.maxAttempts); got != tc.want {
+				t.Fatalf("delay(%d, %d) = %v, want %v", tc.failures, tc.maxAttempts, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFail(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		failures    int
+		// want is the block of the account after the failures, zero when it is not blocked
+		want time.Duration
+	}{
+		{"backoff after one failure", 5, 1, time.Second},
+		{"backoff grows", 5, 3, 4 * time.Second},
+		{"backoff capped", 5, 4, 5 * time.Second},
+		{"locked at max attempts", 5, 5, 15 * time.Minute},
+		{"lockout disabled", 0, 10, 0},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := testPolicy
+			policy.MaxAttempts = tc.maxAttempts
+			g := NewGuard(NewMemoryStore(), policy)
+
+			account, ip := AccountKey("victim@example.com"), IPKey("1.2.3.4")
+			for i := 0; i < tc.failures; i++ {
+				if err := g.Fail(account, ip); err != nil {
+					t.Fatalf("Fail: %v", err)
+				}
+			}
+
+			wait, err := g.Check(account)
+			if err != nil {
+				t.Fatalf("Check: %v", err)
+			}
+			// The store counts from the moment it blocked, allow for the time the test took since
+			if wait > tc.want || wait < tc.want-time.Second {
+				t.Fatalf("Check after %d failures = %v, want %v", tc.failures, wait, tc.want)
+			}
+		})
+	}
+}
+
+func TestSucceedResetsOnlyTheAccount(t *testing.T) {
+	g := NewGuard(NewMemoryStore(), testPolicy)
+	account, ip := AccountKey("victim@example.com"), IPKey("1.2.3.4")
+	for i := 0; i < testPolicy.MaxAttempts; i++ {
+		if err := g.Fail(account, ip); err != nil {
+			t.Fatalf("Fail: %v", err)
+		}
+	}
+
+	if err := g.Succeed(account); err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+	if wait, _ := g.Check(account); wait != 0 {
+		t.Fatalf("account still blocked for %v after a successful login", wait)
+	}
+	if wait, _ := g.Check(ip); wait <= 0 {
+		t.Fatal("IP block was cleared by a successful login")
+	}
+
+	// The account counter starts over, the next failure only backs off
+	if err := g.Fail(account, ip); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if wait, _ := g.Check(account); wait > testPolicy.BackoffBase {
+		t.Fatalf("account blocked for %v after one failure, want at most %v", wait, testPolicy.BackoffBase)
+	}
+}
+
+func TestAccountKeyNormalizesEmail(t *testing.T) {
+	if got, want := AccountKey("  Victim@Example.com "), AccountKey("victim@example.com"); got != want {
+		t.Fatalf("AccountKey = %q, want %q", got, want)
+	}
+}
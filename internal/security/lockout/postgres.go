@@ -0,0 +1,103 @@
+package lockout
+
+import (
+	"database/sql"
+	"time"
+)
+
+// postgresStore keeps counters in the login_attempts table, so they survive restarts
+// and are shared by every instance. All times are computed by the database.
+type postgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore returns a Store backed by the login_attempts table
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Status(key string) (Entry, error) {
+	entry := Entry{Key: key}
+	var seconds float64
+	err := s.db.QueryRow(`
+		SELECT failures, COALESCE(GREATEST(EXTRACT(EPOCH FROM locked_until - NOW()), 0), 0)
+		FROM login_attempts
+		WHERE key = $1`,
+		key,
+	).Scan(&entry.Failures, &seconds)
+	if err == sql.ErrNoRows {
+		return entry, nil
+	}
+	entry.RetryAfter = time.Duration(seconds * float64(time.Second))
+	return entry, err
+}
+
+func (s *postgresStore) Fail(key string, window time.Duration) (int, error) {
+	var failures int
+	err := s.db.QueryRow(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES ($1, 1, NOW())
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE
+				WHEN login_attempts.last_failure_at < NOW() - make_interval(secs => $2) THEN 1
+				ELSE login_attempts.failures + 1
+			END,
+			last_failure_at = NOW()
+		RETURNING failures`,
+		key, window.Seconds(),
+	).Scan(&failures)
+	return failures, err
+}
+
+func (s *postgresStore) Block(key string, d time.Duration) error {
+	_, err := s.db.Exec(`
+		UPDATE login_attempts
+		SET locked_until = GREATEST(COALESCE(locked_until, NOW()), NOW() + make_interval(secs => $2))
+		WHERE key = $1`,
+		key, d.Seconds(),
+	)
+	return err
+}
+
+func (s *postgresStore) Reset(key string) (bool, error) {
+	res, err := s.db.Exec(`DELETE FROM login_attempts WHERE key = $1`, key)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows > 0, err
+}
+
+func (s *postgresStore) Blocked() ([]Entry, error) {
+	rows, err := s.db.Query(`
+		SELECT key, failures, EXTRACT(EPOCH FROM locked_until - NOW())
+		FROM login_attempts
+		WHERE locked_until > NOW()
+		ORDER BY locked_until DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	blocked := []Entry{}
+	for rows.Next() {
+		var entry Entry
+		var seconds float64
+		if err := rows.Scan(&entry.Key, &entry.Failures, &seconds); err != nil {
+			return nil, err
+		}
+		entry.RetryAfter = time.Duration(seconds * float64(time.Second))
+		blocked = append(blocked, entry)
+	}
+	return blocked, rows.Err()
+}
+
+func (s *postgresStore) Prune(window time.Duration) error {
+	_, err := s.db.Exec(`
+		DELETE FROM login_attempts
+		WHERE last_failure_at < NOW() - make_interval(secs => $1)
+		AND (locked_until IS NULL OR locked_until < NOW())`,
+		window.Seconds(),
+	)
+	return err
+}
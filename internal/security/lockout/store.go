@@ -0,0 +1,139 @@
+package lockout
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry is the failure state of one key
+type Entry struct {
+	Key      string
+	Failures int
+	// RetryAfter is how long the key stays blocked, zero when it is not blocked
+	RetryAfter time.Duration
+}
+
+// Store keeps failure counters. Implementations must be safe for concurrent use.
+type Store interface {
+	// Status returns the state of a key, a zero Entry when nothing was recorded
+	Status(key string) (Entry, error)
+	// Fail counts a failure and returns the new count.
+	// The count starts over when the previous failure is older than window.
+	Fail(key string, window time.Duration) (int, error)
+	// Block keeps the key blocked for at least d from now
+	Block(key string, d time.Duration) error
+	// Reset forgets a key and reports whether anything was recorded for it
+	Reset(key string) (bool, error)
+	// Blocked lists the keys that are currently blocked, longest block first
+	Blocked() ([]Entry, error)
+	// Prune drops keys without a block whose last failure is older than window
+	Prune(window time.Duration) error
+}
+
+// memoryStore keeps counters in process memory. Counters are lost on restart and
+// not shared between instances, so it is only meant for development and single instance deployments.
+type memoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// NewMemoryStore returns an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{entries: map[string]*memoryEntry{}}
+}
+
+func (s *memoryStore) Status(key string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		return Entry{Key: key}, nil
+	}
+	return e.entry(key, time.Now()), nil
+}
+
+func (s *memoryStore) Fail(key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	if now.Sub(e.lastFailure) > window {
+		e.failures = 0
+	}
+	e.failures++
+	e.lastFailure = now
+	return e.failures, nil
+}
+
+func (s *memoryStore) Block(key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &memoryEntry{}
+		s.entries[key] = e
+	}
+	if until := time.Now().Add(d); until.After(e.lockedUntil) {
+		e.lockedUntil = until
+	}
+	return nil
+}
+
+func (s *memoryStore) Reset(key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.entries[key]
+	delete(s.entries, key)
+	return ok, nil
+}
+
+func (s *memoryStore) Blocked() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	blocked := []Entry{}
+	for key, e := range s.entries {
+		if entry := e.entry(key, now); entry.RetryAfter > 0 {
+			blocked = append(blocked, entry)
+		}
+	}
+	sort.Slice(blocked, func(i, j int) bool { return blocked[i].RetryAfter > blocked[j].RetryAfter })
+	return blocked, nil
+}
+
+func (s *memoryStore) Prune(window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range s.entries {
+		if now.After(e.lockedUntil) && now.Sub(e.lastFailure) > window {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+func (e *memoryEntry) entry(key string, now time.Time) Entry {
+	entry := Entry{Key: key, Failures: e.failures}
+	if e.lockedUntil.After(now) {
+		entry.RetryAfter = e.lockedUntil.Sub(now)
+	}
+	return entry
+}
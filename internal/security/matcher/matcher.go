@@ -0,0 +1,161 @@
+// Package matcher decides whether a permission grant covers a required permission.
+// Permission names read resource:action[:scope], for example user:update:self.
+// Only all, self and "*" are scopes. Any other third part belongs to the action, so user:promote:admin
+// is the action promote:admin of user and user:promote does not cover it.
+//
+// A grant covers a requirement when every part does:
+//   - "*" as the resource or action covers any value, so user:* covers every user permission and *:read every read
+//   - "*" alone covers every permission
+//   - a grant without a scope covers the action at any scope, user:read covers user:read:all and user:read:self
+//   - the scope all covers self, user:update:all covers user:update:self
+//
+// Anything else is compared exactly. A scoped grant never covers an unscoped requirement.
+package matcher
+
+import "strings"
+
+// Wildcard matches any resource or action, and any scope
+const Wildcard = "*"
+
+const (
+	ScopeAll  = "all"
+	ScopeSelf = "self"
+)
+
+// scopes are the known scopes, a third part that is none of them is part of the action
+var scopes = map[string]bool{
+	ScopeAll:  true,
+	ScopeSelf: true,
+	Wildcard:  true,
+}
+
+// scopeImplies lists the narrower scopes each scope covers
+var scopeImplies = map[string][]string{
+	ScopeAll: {ScopeSelf},
+}
+
+// Permission is a permission name split in its parts, an empty Scope means none
+type Permission struct {
+	Resource string
+	Action   string
+	Scope    string
+}
+
+// Parse splits a permission name. Everything after the second colon is the scope when it is a known scope,
+// otherwise it is part of the action.
+func Parse(name string) Permission {
+	if name == Wildcard {
+		return Permission{Resource: Wildcard, Action: Wildcard}
+	}
+	parts := strings.SplitN(name, ":", 3)
+	p := Permission{Resource: parts[0]}
+	if len(parts) > 1 {
+		p.Action = parts[1]
+	}
+	if len(parts) > 2 {
+		if scopes[parts[2]] {
+			p.Scope = parts[2]
+		} else {
+			p.Action += ":" + parts[2]
+		}
+	}
+	// A wildcard scope covers the same as no scope
+	if p.Scope == Wildcard {
+		p.Scope = ""
+	}
+	return p
+}
+
+// String joins the parts back into a permission name
+func (p Permission) String() string {
+	if p.Resource == Wildcard && p.Action == Wildcard && p.Scope == "" {
+		return Wildcard
+	}
+	name := p.Resource
+	if p.Action != "" || p.Scope != "" {
+		name += ":" + p.Action
+	}
+	if p.Scope != "" {
+		name += ":" + p.Scope
+	}
+	return name
+}
+
+// Covers reports whether the grant covers the required permission
+func Covers(grant, required string) bool {
+	if grant == required {
+		return true
+	}
+	g, req := Parse(grant), Parse(required)
+	return segmentCovers(g.Resource, req.Resource) &&
+		segmentCovers(g.Action, req.Action) &&
+		scopeCovers(g.Scope, req.Scope)
+}
+
+// CoversAny reports whether any of the grants covers the required permission
+func CoversAny(grants []string, required string) bool {
+	for _, grant := range grants {
+		if Covers(grant, required) {
+			return true
+		}
+	}
+	return false
+}
+
+// Intersect returns the narrowest permission covered by both a and b, it covers exactly what both cover.
+// It is used to narrow the grants of a token to its scope.
+func Intersect(a, b string) (string, bool) {
+	if a == b {
+		return a, true
+	}
+	pa, pb := Parse(a), Parse(b)
+	resource, ok := intersectSegment(pa.Resource, pb.Resource)
+	if !ok {
+		return "", false
+	}
+	action, ok := intersectSegment(pa.Action, pb.Action)
+	if !ok {
+		return "", false
+	}
+	scope, ok := intersectScope(pa.Scope, pb.Scope)
+	if !ok {
+		return "", false
+	}
+	return Permission{Resource: resource, Action: action, Scope: scope}.String(), true
+}
+
+func segmentCovers(grant, required string) bool {
+	return grant == Wildcard || grant == required
+}
+
+func scopeCovers(grant, required string) bool {
+	if grant == "" || grant == required {
+		return true
+	}
+	for _, implied := range scopeImplies[grant] {
+		if implied == required {
+			return true
+		}
+	}
+	return false
+}
+
+func intersectSegment(a, b string) (string, bool) {
+	switch {
+	case a == b || b == Wildcard:
+		return a, true
+	case a == Wildcard:
+		return b, true
+	}
+	return "", false
+}
+
+func intersectScope(a, b string) (string, bool) {
+	switch {
+	case scopeCovers(a, b):
+		return b, true
+	case scopeCovers(b, a):
+		return a, true
+	}
+	return "", false
+}
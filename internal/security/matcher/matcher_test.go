@@ -0,0 +1,172 @@
+package matcher
+
+import "testing"
+
+func TestCovers(t *testing.T) {
+	tests := []struct {
+		name     string
+		grant    string
+		required string
+		want     bool
+	}{
+		// Exact names
+		{"exact", "role:read", "role:read", true},
+		{"other action", "role:read", "role:create", false},
+		{"other resource", "role:read", "permission:read", false},
+		{"exact scoped", "user:read:all", "user:read:all", true},
+		{"exact unusual name", "oauth:client:manage", "oauth:client:manage", true},
+
+		// Everything
+		{"star covers unscoped", "*", "key:manage", true},
+		{"star covers scoped", "*", "user:update:self", true},
+		{"star covers star", "*", "*", true},
+
+		// Resource wildcard
+		{"resource wildcard action", "user:*", "user:demote", true},
+		{"resource wildcard scoped", "user:*", "user:read:all", true},
+		{"resource wildcard other resource", "user:*", "role:read", false},
+		{"resource wildcard scoped grant", "user:*:self", "user:update:self", true},
+		{"resource wildcard scoped grant wrong scope", "user:*:self", "user:update:all", false},
+
+		// Action wildcard
+		{"action wildcard", "*:read", "role:read", true},
+		{"action wildcard scoped", "*:read", "user:read:self", true},
+		{"action wildcard other action", "*:read", "user:update:self", false},
+		{"action wildcard scoped grant", "*:read:all", "user:read:self", true},
+
+		// Unscoped grants cover every scope of the action
+		{"unscoped covers all", "user:read", "user:read:all", true},
+		{"unscoped covers self", "user:read", "user:read:self", true},
+		{"unscoped other action", "user:read", "user:update:self", false},
+		{"scope wildcard", "user:read:*", "user:read:self", true},
+		{"scope wildcard unscoped", "user:read:*", "user:read", true},
+
+		// Scope hierarchy
+		{"all covers self", "user:update:all", "user:update:self", true},
+		{"self does not cover all", "user:update:self", "user:update:all", false},
+		{"scoped does not cover unscoped", "user:read:all", "user:read", false},
+		{"all does not cover other actions", "user:promote:all", "user:promote:admin", false},
+		{"all across actions", "user:update:all", "user:delete:self", false},
+
+		// A third part that is no scope belongs to the action, the shorter name is another permission
+		{"promote does not cover promote admin", "user:promote", "user:promote:admin", false},
+		{"role permission does not cover its manage", "role:permission", "role:permission:manage", false},
+		{"oauth client does not cover its manage", "oauth:client", "oauth:client:manage", false},
+		{"user role does not cover its manage", "user:role", "user:role:manage", false},
+		{"promote admin does not cover promote", "user:promote:admin", "user:promote", false},
+		{"action wildcard of the last part", "*:manage", "user:role:manage", false},
+		{"exact seeded name", "user:promote:admin", "user:promote:admin", true},
+		{"resource wildcard covers long action", "user:*", "user:promote:admin", true},
+		{"resource wildcard covers long action of role", "role:*", "role:permission:manage", true},
+		{"scoped wildcard does not cover long action", "user:*:all", "user:promote:admin", false},
+
+		// Wildcard requirements are only covered by wildcards
+		{"literal does not cover resource wildcard", "user:read", "user:*", false},
+		{"literal does not cover action wildcard", "role:read", "*:read", false},
+		{"literal does not cover star", "key:manage", "*", false},
+		{"resource wildcard does not cover star", "user:*", "*", false},
+		{"action wildcard does not cover star", "*:read", "*", false},
+		{"resource wildcard covers itself", "user:*", "user:*", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Covers(tc.grant, tc.required); got != tc.want {
+				t.Fatalf("Covers(%q, %q) = %v, want %v", tc.grant, tc.required, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCoversAny(t *testing.T) {
+	grants := []string{"role:read", "user:update:all"}
+	if !CoversAny(grants, "user:update:self") {
+		t.Fatal("expected user:update:self to be covered")
+	}
+	if CoversAny(grants, "role:delete") {
+		t.Fatal("expected role:delete not to be covered")
+	}
+	if CoversAny(nil, "role:read") {
+		t.Fatal("expected no grants to cover nothing")
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	tests := []struct {
+		name   string
+		a, b   string
+		want   string
+		wantOK bool
+	}{
+		{"equal", "role:read", "role:read", "role:read", true},
+		{"star narrows to the other", "*", "user:read:all", "user:read:all", true},
+		{"star with star", "*", "*", "*", true},
+		{"resource and action wildcards", "user:*", "*:read", "user:read", true},
+		{"resource wildcard and literal", "user:*", "user:demote", "user:demote", true},
+		{"all and self", "user:update:all", "user:update:self", "user:update:self", true},
+		{"unscoped and scoped", "user:read", "user:read:all", "user:read:all", true},
+		{"wildcard scope and scoped", "user:*:all", "*:update:self", "user:update:self", true},
+		{"disjoint resources", "user:read:all", "role:read", "", false},
+		{"resource wildcard and scoped literal", "user:*", "user:read:self", "user:read:self", true},
+		{"disjoint scopes", "user:update:self", "user:promote:admin", "", false},
+		{"resource wildcard and long action", "user:*", "user:promote:admin", "user:promote:admin", true},
+		{"short and long action", "user:promote", "user:promote:admin", "", false},
+		{"scoped and unscoped literal", "user:read:all", "user:read", "user:read:all", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, pair := range [][2]string{{tc.a, tc.b}, {tc.b, tc.a}} {
+				got, ok := Intersect(pair[0], pair[1])
+				if ok != tc.wantOK || got != tc.want {
+					t.Fatalf("Intersect(%q, %q) = %q, %v, want %q, %v", pair[0], pair[1], got, ok, tc.want, tc.wantOK)
+				}
+			}
+		})
+	}
+}
+
+// The intersection must cover exactly what both permissions cover, LimitToScope relies on it
+func TestIntersectCoversWhatBothCover(t *testing.T) {
+	patterns := []string{
+		"*", "user:*", "*:read", "user:read", "user:read:all", "user:read:self", "user:read:*",
+		"user:*:self", "*:update:all", "user:update:self", "role:read", "key:manage",
+		"user:promote", "user:promote:admin", "user:promote:all", "role:permission", "*:manage",
+	}
+	required := []string{
+		"user:read:all", "user:read:self", "user:read", "user:update:all", "user:update:self",
+		"user:demote", "role:read", "role:create", "key:manage", "permission:read",
+		"user:promote:admin", "user:promote:moderator", "user:role:manage", "role:permission:manage", "oauth:client:manage",
+	}
+	for _, a := range patterns {
+		for _, b := range patterns {
+			meet, ok := Intersect(a, b)
+			for _, req := range required {
+				both := Covers(a, req) && Covers(b, req)
+				got := ok && Covers(meet, req)
+				if got != both {
+					t.Errorf("Intersect(%q, %q) = %q, %v covers %q: %v, want %v", a, b, meet, ok, req, got, both)
+				}
+			}
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		want Permission
+	}{
+		{"*", Permission{Resource: "*", Action: "*"}},
+		{"user:*", Permission{Resource: "user", Action: "*"}},
+		{"user:read:all", Permission{Resource: "user", Action: "read", Scope: "all"}},
+		{"user:read:*", Permission{Resource: "user", Action: "read"}},
+		{"user:read:self", Permission{Resource: "user", Action: "read", Scope: "self"}},
+		{"user:promote:admin", Permission{Resource: "user", Action: "promote:admin"}},
+		{"oauth:client:manage", Permission{Resource: "oauth", Action: "client:manage"}},
+		{"a:b:c:d", Permission{Resource: "a", Action: "b:c:d"}},
+	}
+	for _, tc := range tests {
+		if got := Parse(tc.name); got != tc.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
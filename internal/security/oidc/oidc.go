@@ -0,0 +1,202 @@
+// Package oidc holds the protocol pieces of the OAuth2 / OpenID Connect provider:
+// scopes, PKCE, redirect URI rules and ID tokens. Storage lives in the services package.
+package oidc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+)
+
+// Scopes with a fixed meaning. Any other scope is the name of a permission,
+// it is granted only to users who hold that permission.
+const (
+	ScopeOpenID      = "openid"
+	ScopeProfile     = "profile"
+	ScopeEmail       = "email"
+	ScopeRoles       = "roles"
+	ScopePermissions = "permissions"
+)
+
+// StandardScopes lists the scopes that are not permissions
+var StandardScopes = []string{ScopeOpenID, ScopeProfile, ScopeEmail, ScopeRoles, ScopePermissions}
+
+// Grant types a client can be allowed to use at the token endpoint
+const (
+	GrantAuthorizationCode = "authorization_code"
+	// GrantClientCredentials makes a client a service account that acts with the permissions of its own roles
+	GrantClientCredentials = "client_credentials"
+)
+
+// CodeChallengeMethodS256 is the only PKCE method accepted, "plain" offers no protection
+const CodeChallengeMethodS256 = "S256"
+
+// ErrInvalidRedirectURI is returned for redirect URIs that cannot be registered
+var ErrInvalidRedirectURI = errors.New("redirect URIs must be absolute https URLs without a fragment, http is only allowed for loopback addresses")
+
+// IsStandardScope reports whether the scope has a fixed meaning
+func IsStandardScope(scope string) bool {
+	for _, s := range StandardScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseScope splits a space separated scope parameter, dropping duplicates
+func ParseScope(scope string) []string {
+	var scopes []string
+	seen := make(map[string]bool)
+	for _, s := range strings.Fields(scope) {
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	return scopes
+}
+
+// HasScope reports whether scope is in scopes
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckRedirectURI validates a redirect URI before it is added to the allow-list of a client.
+// Redirect URIs are compared exactly at authorization time, so no normalisation is done here.
+func CheckRedirectURI(redirectURI string) error {
+	u, err := url.Parse(redirectURI)
+	if err != nil || !u.IsAbs() || u.Host == "" || u.Fragment != "" || strings.Contains(redirectURI, "#") {
+		return ErrInvalidRedirectURI
+	}
+	switch u.Scheme {
+	case "https":
+		return nil
+	case "http":
+		// Native apps listen on a loopback port (RFC 8252)
+		if u.Hostname() == "localhost" {
+			return nil
+		}
+		if ip := net.ParseIP(u.Hostname()); ip != nil && ip.IsLoopback() {
+			return nil
+		}
+	}
+	return ErrInvalidRedirectURI
+}
+
+// ValidCodeChallenge reports whether a code_challenge has the shape of a base64url encoded SHA-256 hash
+func ValidCodeChallenge(challenge string) bool {
+	decoded, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(decoded) == sha256.Size
+}
+
+// VerifyCodeChallenge checks a PKCE code_verifier against the S256 code_challenge of the authorization request
+func VerifyCodeChallenge(challenge, verifier string) bool {
+	// RFC 7636 section 4.1: 43 to 128 unreserved characters
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, c := range verifier {
+		if !(c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == '_' || c == '~') {
+			return false
+		}
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+// UserClaims are the claims about a user released to a client, each group only with its scope
+type UserClaims struct {
+	// profile
+	Name              string `json:"name,omitempty"`
+	GivenName         string `json:"given_name,omitempty"`
+	FamilyName        string `json:"family_name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	UpdatedAt         int64  `json:"updated_at,omitempty"`
+	// email
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	// roles and permissions
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+// NewUserClaims builds the claims a client may see for the granted scopes
+func NewUserClaims(user models.User, roles, permissions, scopes []string) UserClaims {
+	var claims UserClaims
+
+	if HasScope(scopes, ScopeProfile) {
+		claims.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+		claims.GivenName = user.FirstName
+		claims.FamilyName = user.LastName
+		claims.PreferredUsername = user.Username
+		if !user.UpdatedAt.IsZero() {
+			claims.UpdatedAt = user.UpdatedAt.Unix()
+		}
+	}
+	if HasScope(scopes, ScopeEmail) {
+		emailVerified := user.EmailVerified
+		claims.Email = user.Email
+		claims.EmailVerified = &emailVerified
+	}
+	if HasScope(scopes, ScopeRoles) {
+		claims.Roles = roles
+	}
+	if HasScope(scopes, ScopePermissions) {
+		claims.Permissions = permissions
+	}
+	return claims
+}
+
+// IDClaims are the claims of an ID token
+type IDClaims struct {
+	Nonce           string `json:"nonce,omitempty"`
+	AuthorizedParty string `json:"azp,omitempty"`
+	AuthTime        int64  `json:"auth_time,omitempty"`
+	UserClaims
+	jwt.RegisteredClaims
+}
+
+// IssueIDToken signs an ID token for the client with the active signing key, so relying parties can verify it against the JWKS.
+// authTime is when the user logged in, the zero time leaves auth_time out.
+func IssueIDToken(userID, clientID, nonce string, authTime time.Time, userClaims UserClaims) (string, error) {
+	cfg := config.GetConfig()
+	now := time.Now()
+
+	claims := IDClaims{
+		Nonce:           nonce,
+		AuthorizedParty: clientID,
+		UserClaims:      userClaims,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    cfg.JWT.Issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(cfg.OAuth.IDTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+	if !authTime.IsZero() {
+		claims.AuthTime = authTime.Unix()
+	}
+
+	return keys.SigningKey().Sign(claims)
+}
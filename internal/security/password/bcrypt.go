@@ -0,0 +1,38 @@
+package password
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// bcryptHasher keeps the modular crypt format bcrypt always used ($2a$<cost>$<salt+hash>),
+// so hashes stored before the hasher existed verify unchanged.
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcrypt returns a bcrypt hasher with the given cost
+func NewBcrypt(cost int) Hasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Algorithm() string {
+	return AlgBcrypt
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h *bcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
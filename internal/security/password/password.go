@@ -0,0 +1,90 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+// Algorithm identifiers, they match the first field of the encoded hashes
+const (
+	AlgArgon2id = "argon2id"
+	AlgBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownHash   = errors.New("password: unknown hash format")
+	ErrMalformedHash = errors.New("password: malformed hash")
+)
+
+// Hasher hashes passwords into self-describing strings that carry the algorithm and its parameters
+type Hasher interface {
+	// Algorithm returns the identifier of the hashing algorithm
+	Algorithm() string
+	// Hash returns the encoded hash of password with a fresh salt
+	Hash(password string) (string, error)
+	// Verify reports whether password matches an encoded hash made by this algorithm
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether an encoded hash of this algorithm was made with other parameters
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	hashers  map[string]Hasher
+	current  Hasher
+	initOnce sync.Once
+)
+
+// load builds the hashers from PASSWORD_HASH_* settings
+func load() {
+	initOnce.Do(func() {
+		cfg := config.GetConfig().Password
+
+		hashers = map[string]Hasher{
+			AlgArgon2id: NewArgon2id(cfg.Argon2Memory, cfg.Argon2Iterations, cfg.Argon2Parallelism),
+			AlgBcrypt:   NewBcrypt(cfg.BcryptCost),
+		}
+		current = hashers[cfg.HashAlgorithm]
+	})
+}
+
+// Default returns the hasher new passwords are hashed with
+func Default() Hasher {
+	load()
+	return current
+}
+
+// Hash hashes a password with the configured algorithm
+func Hash(password string) (string, error) {
+	return Default().Hash(password)
+}
+
+// Verify checks a password against a stored hash of any supported algorithm.
+// rehash is true when the password matched but the hash uses another algorithm or outdated parameters,
+// the caller should then store a new hash while it still has the plain password.
+func Verify(password, encoded string) (ok bool, rehash bool, err error) {
+	load()
+
+	hasher, err := identify(encoded)
+	if err != nil {
+		return false, false, err
+	}
+	ok, err = hasher.Verify(password, encoded)
+	if err != nil || !ok {
+		return false, false, err
+	}
+	return true, hasher != current || hasher.NeedsRehash(encoded), nil
+}
+
+// identify picks the hasher of an encoded hash
+func identify(encoded string) (Hasher, error) {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return hashers[AlgArgon2id], nil
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return hashers[AlgBcrypt], nil
+	}
+	return nil, ErrUnknownHash
+}
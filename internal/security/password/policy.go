@@ -0,0 +1,242 @@
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/breach"
+)
+
+// Violation codes returned by the policy checks
+const (
+	ViolationTooShort      = "too_short"
+	ViolationTooLong       = "too_long"
+	ViolationMissingLower  = "missing_lower"
+	ViolationMissingUpper  = "missing_upper"
+	ViolationMissingDigit  = "missing_digit"
+	ViolationMissingSymbol = "missing_symbol"
+	ViolationBanned        = "banned"
+	ViolationSimilar       = "similar_to_account"
+	ViolationReused        = "reused"
+	ViolationBreached      = "breached"
+)
+
+// bcryptMaxBytes is the longest input bcrypt accepts
+const bcryptMaxBytes = 72
+
+// minSimilarityLength keeps very short usernames from banning every password containing them
+const minSimilarityLength = 3
+
+// builtinBanned are common passwords that pass the length rules, always rejected
+var builtinBanned = []string{
+	"password", "password1", "password12", "password123", "passw0rd", "p@ssw0rd", "p@ssword",
+	"12345678", "123456789", "1234567890", "0123456789", "87654321", "11111111", "00000000",
+	"qwertyui", "qwerty123", "qwertyuiop", "1q2w3e4r", "1qaz2wsx", "zaq12wsx", "asdfghjk",
+	"iloveyou", "sunshine", "princess", "football", "baseball", "superman", "trustno1",
+	"letmein1", "welcome1", "welcome123", "admin123", "administrator", "changeme", "abcd1234",
+}
+
+// Violation is one rule a password does not satisfy
+type Violation struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Subject is the account a password is being set for, used by the similarity rule
+type Subject struct {
+	Username string
+	Email    string
+}
+
+// Policy holds the rules every new password is checked against
+type Policy struct {
+	MinLength       int
+	MaxLength       int
+	RequiredClasses []string
+	CheckSimilarity bool
+	History         int
+
+	banned         map[string]struct{}
+	breached       breach.Checker
+	breachMinCount int
+}
+
+var (
+	policy     *Policy
+	policyOnce sync.Once
+)
+
+// GetPolicy returns the policy built from PASSWORD_* settings, loading the banned list on first use
+func GetPolicy() *Policy {
+	policyOnce.Do(func() {
+		var err error
+		policy, err = NewPolicy(config.GetConfig().Password)
+		if err != nil {
+			log.Fatalf("failed to load password policy: %v", err)
+		}
+	})
+	return policy
+}
+
+// NewPolicy builds a policy from the password settings
+func NewPolicy(cfg config.PasswordConfig) (*Policy, error) {
+	p := &Policy{
+		MinLength:       cfg.MinLength,
+		MaxLength:       cfg.MaxLength,
+		RequiredClasses: cfg.RequiredClasses,
+		CheckSimilarity: cfg.CheckSimilarity,
+		History:         cfg.History,
+		banned:          make(map[string]struct{}),
+	}
+	for _, pw := range builtinBanned {
+		p.banned[pw] = struct{}{}
+	}
+
+	if cfg.BannedListPath != "" {
+		if err := p.loadBanned(cfg.BannedListPath); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.BreachSource != "" {
+		checker, err := breach.Open(cfg.BreachSource, cfg.BreachPath)
+		if err != nil {
+			return nil, err
+		}
+		// A Bloom filter only holds hashes seen at least as often as it was built for
+		if bloom, ok := checker.(*breach.Bloom); ok && bloom.MinCount != cfg.BreachMinCount {
+			return nil, fmt.Errorf("Bloom filter %s was built with -min-count %d but BREACHED_PASSWORDS_MIN_COUNT is %d",
+				cfg.BreachPath, bloom.MinCount, cfg.BreachMinCount)
+		}
+		p.breached = checker
+		p.breachMinCount = cfg.BreachMinCount
+	}
+	return p, nil
+}
+
+// loadBanned adds the passwords of a file, one per line, to the banned list
+func (p *Policy) loadBanned(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			p.banned[strings.ToLower(line)] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// Check returns every rule the password breaks, except password history which needs the stored hashes.
+// The password is checked exactly as given, surrounding whitespace counts.
+func (p *Policy) Check(pw string, subject Subject) []Violation {
+	violations := []Violation{}
+
+	length := utf8.RuneCountInString(pw)
+	if length < p.MinLength {
+		violations = append(violations, Violation{ViolationTooShort, fmt.Sprintf("Password must be at least %d characters long", p.MinLength)})
+	}
+	if length > p.MaxLength {
+		violations = append(violations, Violation{ViolationTooLong, fmt.Sprintf("Password must be at most %d characters long", p.MaxLength)})
+	} else if len(pw) > bcryptMaxBytes && Default().Algorithm() == AlgBcrypt {
+		violations = append(violations, Violation{ViolationTooLong, fmt.Sprintf("Password must be at most %d bytes long", bcryptMaxBytes)})
+	}
+
+	for _, class := range p.RequiredClasses {
+		if v, ok := checkClass(pw, class); !ok {
+			violations = append(violations, v)
+		}
+	}
+
+	lower := strings.ToLower(pw)
+	if _, ok := p.banned[lower]; ok {
+		violations = append(violations, Violation{ViolationBanned, "Password is too common"})
+	}
+
+	if p.CheckSimilarity && similar(lower, subject) {
+		violations = append(violations, Violation{ViolationSimilar, "Password must not contain your username or email address"})
+	}
+
+	if p.breached != nil && pw != "" {
+		count, err := p.breached.Count(pw)
+		if err != nil {
+			// An unreadable corpus should not lock everybody out of setting a password
+			log.Println("Failed to check breached passwords:", err)
+		} else if count >= p.breachMinCount {
+			violations = append(violations, Violation{ViolationBreached, "Password has appeared in a data breach, choose a different one"})
+		}
+	}
+
+	return violations
+}
+
+// CheckHistory returns a reuse violation when the password matches one of the given hashes,
+// which should be the current hash followed by the previous ones, newest first.
+func (p *Policy) CheckHistory(pw string, hashes []string) []Violation {
+	if p.History <= 0 {
+		return nil
+	}
+	if len(hashes) > p.History {
+		hashes = hashes[:p.History]
+	}
+
+	for _, hash := range hashes {
+		if ok, _, err := Verify(pw, hash); err == nil && ok {
+			return []Violation{{ViolationReused, fmt.Sprintf("Password must differ from your last %d passwords", p.History)}}
+		}
+	}
+	return nil
+}
+
+// checkClass reports whether the password contains a character of the class
+func checkClass(pw, class string) (Violation, bool) {
+	var match func(rune) bool
+	var v Violation
+
+	switch class {
+	case "lower":
+		match, v = unicode.IsLower, Violation{ViolationMissingLower, "Password must contain a lowercase letter"}
+	case "upper":
+		match, v = unicode.IsUpper, Violation{ViolationMissingUpper, "Password must contain an uppercase letter"}
+	case "digit":
+		match, v = unicode.IsDigit, Violation{ViolationMissingDigit, "Password must contain a digit"}
+	case "symbol":
+		match = func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r) }
+		v = Violation{ViolationMissingSymbol, "Password must contain a symbol"}
+	default:
+		return Violation{}, true
+	}
+
+	return v, strings.IndexFunc(pw, match) >= 0
+}
+
+// similar reports whether a lowercased password contains, or is contained in, the username,
+// the email address or the local part of the email address
+func similar(lower string, subject Subject) bool {
+	if lower == "" {
+		return false
+	}
+	email := strings.ToLower(strings.TrimSpace(subject.Email))
+	local, _, _ := strings.Cut(email, "@")
+
+	for _, identifier := range []string{strings.ToLower(strings.TrimSpace(subject.Username)), email, local} {
+		if utf8.RuneCountInString(identifier) < minSimilarityLength {
+			continue
+		}
+		if strings.Contains(lower, identifier) || strings.Contains(identifier, lower) {
+			return true
+		}
+	}
+	return false
+}
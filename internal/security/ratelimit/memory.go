@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// cleanupInterval is how often idle buckets are dropped from memory
+const cleanupInterval = 10 * time.Minute
+
+// memoryStore keeps buckets in process memory, every instance limits on its own
+type memoryStore struct {
+	mu          sync.Mutex
+	buckets     map[string]*bucket
+	lastCleanup time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	limit   Limit
+}
+
+// NewMemoryStore returns an in-memory Store
+func NewMemoryStore() Store {
+	return &memoryStore{buckets: map[string]*bucket{}, lastCleanup: time.Now()}
+}
+
+func (s *memoryStore) Take(keys []string, limit Limit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.cleanup(now)
+
+	buckets := make([]*bucket, len(keys))
+	tokens := make([]float64, len(keys))
+	for i, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: float64(limit.Burst), updated: now}
+			s.buckets[key] = b
+		}
+		b.limit = limit
+		buckets[i], tokens[i] = b, refill(b.tokens, now.Sub(b.updated), limit)
+	}
+
+	tokens, allowed, wait := take(tokens, limit)
+	for i, b := range buckets {
+		b.tokens, b.updated = tokens[i], now
+	}
+	return allowed, wait, nil
+}
+
+// cleanup drops buckets that have refilled completely, they behave like new ones
+func (s *memoryStore) cleanup(now time.Time) {
+	if now.Sub(s.lastCleanup) < cleanupInterval {
+		return
+	}
+	s.lastCleanup = now
+
+	for key, b := range s.buckets {
+		if refill(b.tokens, now.Sub(b.updated), b.limit) >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
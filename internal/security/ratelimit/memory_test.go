@@ -0,0 +1,34 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTakesFromEveryBucketOrNone(t *testing.T) {
+	s := NewMemoryStore()
+	limit := Limit{Rate: 1 / time.Hour.Seconds(), Burst: 2}
+
+	// Empty the email bucket
+	for i := 0; i < 2; i++ {
+		if allowed, _, _ := s.Take([]string{"email:victim"}, limit); !allowed {
+			t.Fatalf("request %d rejected", i)
+		}
+	}
+
+	// Requests rejected by the email bucket must not use up the IP bucket
+	for i := 0; i < 3; i++ {
+		allowed, wait, err := s.Take([]string{"ip:1.2.3.4", "email:victim"}, limit)
+		if err != nil || allowed || wait <= 0 {
+			t.Fatalf("Take = %v, %v, %v, want rejected with a wait", allowed, wait, err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if allowed, _, _ := s.Take([]string{"ip:1.2.3.4"}, limit); !allowed {
+			t.Fatalf("IP request %d rejected, its bucket was charged by rejected requests", i)
+		}
+	}
+	if allowed, _, _ := s.Take([]string{"ip:1.2.3.4"}, limit); allowed {
+		t.Fatal("IP bucket allowed more than its burst")
+	}
+}
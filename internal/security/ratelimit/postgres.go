@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"database/sql"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// pruneAfter is how long a bucket may stay untouched before it is deleted.
+// Limits must refill completely within this time, which holds for any sensible limit.
+const pruneAfter = 24 * time.Hour
+
+// postgresStore keeps buckets in the rate_limit_buckets table so all instances share them
+type postgresStore struct {
+	db *sql.DB
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// NewPostgresStore returns a Store backed by the rate_limit_buckets table
+func NewPostgresStore(db *sql.DB) Store {
+	return &postgresStore{db: db}
+}
+
+func (s *postgresStore) Take(keys []string, limit Limit) (bool, time.Duration, error) {
+	s.prune()
+
+	// Lock the rows in a fixed order so concurrent requests sharing keys cannot deadlock
+	keys = append([]string(nil), keys...)
+	sort.Strings(keys)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, 0, err
+	}
+	defer tx.Rollback()
+
+	tokens := make([]float64, len(keys))
+	for i, key := range keys {
+		// Make sure the row exists so it can be locked
+		_, err = tx.Exec(`
+			INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (key) DO NOTHING`,
+			key, float64(limit.Burst),
+		)
+		if err != nil {
+			return false, 0, err
+		}
+
+		var elapsed float64
+		err = tx.QueryRow(`
+			SELECT tokens, EXTRACT(EPOCH FROM NOW() - updated_at)
+			FROM rate_limit_buckets
+			WHERE key = $1
+			FOR UPDATE`,
+			key,
+		).Scan(&tokens[i], &elapsed)
+		if err != nil {
+			return false, 0, err
+		}
+		tokens[i] = refill(tokens[i], time.Duration(elapsed*float64(time.Second)), limit)
+	}
+
+	tokens, allowed, wait := take(tokens, limit)
+	for i, key := range keys {
+		_, err = tx.Exec(`UPDATE rate_limit_buckets SET tokens = $2, updated_at = NOW() WHERE key = $1`, key, tokens[i])
+		if err != nil {
+			return false, 0, err
+		}
+	}
+	return allowed, wait, tx.Commit()
+}
+
+// prune deletes idle buckets, at most once per cleanupInterval
+func (s *postgresStore) prune() {
+	s.mu.Lock()
+	if time.Since(s.lastPrune) < cleanupInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.mu.Unlock()
+
+	_, err := s.db.Exec(`DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - make_interval(secs => $1)`, pruneAfter.Seconds())
+	if err != nil {
+		log.Println("Failed to prune rate limit buckets:", err)
+	}
+}
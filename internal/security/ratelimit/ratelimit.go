@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/database"
+)
+
+// Limit describes a token bucket: it holds at most Burst tokens and refills Rate tokens per second
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// FromRule converts a configured "requests per period" rule into a token bucket
+func FromRule(rule config.RateLimitRule) Limit {
+	return Limit{
+		Rate:  float64(rule.Requests) / rule.Period.Seconds(),
+		Burst: rule.Requests,
+	}
+}
+
+// Store takes tokens from buckets. Implementations must be safe for concurrent use.
+type Store interface {
+	// Take removes one token from the bucket of every key, or from none of them when any bucket is empty.
+	// In that case it returns false and how long it takes until every bucket has a token again.
+	Take(keys []string, limit Limit) (bool, time.Duration, error)
+}
+
+var (
+	store Store
+	once  sync.Once
+)
+
+// Get returns the store singleton selected by RATE_LIMIT_STORE
+func Get() Store {
+	once.Do(func() {
+		switch config.GetConfig().RateLimit.Store {
+		case "postgres":
+			store = NewPostgresStore(database.Connect())
+		default:
+			store = NewMemoryStore()
+		}
+	})
+	return store
+}
+
+// refill returns the tokens of a bucket that had tokens left elapsed ago
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+// take applies one request to buckets with the given tokens. When every bucket has a token
+// it takes one from each, otherwise it leaves them as they are and returns how long until they all have one.
+func take(tokens []float64, limit Limit) ([]float64, bool, time.Duration) {
+	var wait time.Duration
+	for _, t := range tokens {
+		if t < 1 {
+			if w := time.Duration((1 - t) / limit.Rate * float64(time.Second)); w > wait {
+				wait = w
+			}
+		}
+	}
+	if wait > 0 {
+		return tokens, false, wait
+	}
+
+	left := make([]float64, len(tokens))
+	for i, t := range tokens {
+		left[i] = t - 1
+	}
+	return left, true, 0
+}
@@ -0,0 +1,105 @@
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/keys"
+)
+
+// Purpose identifies what a token may be used for. Each purpose has its own audience,
+// so a token issued for one purpose never validates as another.
+type Purpose string
+
+const (
+	PurposeAccess            Purpose = "access"
+	PurposeEmailVerification Purpose = "email_verification"
+	// PurposeMFAPending is issued after the password step of a login that still needs a second factor
+	PurposeMFAPending Purpose = "mfa_pending"
+	// PurposeOAuthAccess is the access token an OAuth client receives for a user
+	PurposeOAuthAccess Purpose = "oauth_access"
+)
+
+// ErrWrongPurpose is returned when a valid token is presented for another purpose
+var ErrWrongPurpose = errors.New("token was issued for another purpose")
+
+// Claims are the claims of every token issued by the service
+type Claims struct {
+	UserID    string  `json:"user_id,omitempty"`
+	Username  string  `json:"username,omitempty"`
+	UserType  string  `json:"user_type,omitempty"`
+	Email     string  `json:"email,omitempty"`
+	SessionID string  `json:"sid,omitempty"`
+	ClientID  string  `json:"client_id,omitempty"`
+	Scope     string  `json:"scope,omitempty"`
+	Purpose   Purpose `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+// Audience returns the aud claim for tokens of the given purpose.
+// Access tokens use JWT_AUDIENCE as is, other purposes get a suffix.
+func Audience(purpose Purpose) string {
+	audience := config.GetConfig().JWT.Audience
+	if purpose == PurposeAccess {
+		return audience
+	}
+	return fmt.Sprintf("%s:%s", audience, purpose)
+}
+
+// Issue signs a token for the purpose that expires after ttl.
+// Issuer, audience, subject, jti and the time claims are filled in here.
+func Issue(purpose Purpose, claims Claims, ttl time.Duration) (string, error) {
+	cfg := config.GetConfig()
+	now := time.Now()
+
+	// Service account tokens have no user, their subject is the client
+	subject := claims.UserID
+	if subject == "" {
+		subject = claims.ClientID
+	}
+
+	claims.Purpose = purpose
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Issuer:    cfg.JWT.Issuer,
+		Subject:   subject,
+		Audience:  jwt.ClaimStrings{Audience(purpose)},
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		NotBefore: jwt.NewNumericDate(now),
+		IssuedAt:  jwt.NewNumericDate(now),
+		ID:        uuid.NewString(),
+	}
+
+	return keys.SigningKey().Sign(claims)
+}
+
+// Parse validates a token for the purpose and returns its claims.
+// It checks the signature against the keyring, the algorithm allow-list, issuer, audience and expiry with the configured leeway.
+func Parse(purpose Purpose, tokenString string) (*Claims, error) {
+	cfg := config.GetConfig()
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(cfg.JWT.AllowedAlgorithms),
+		jwt.WithIssuer(cfg.JWT.Issuer),
+		jwt.WithAudience(Audience(purpose)),
+		jwt.WithLeeway(cfg.JWT.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+
+	claims := &Claims{}
+	token, err := parser.ParseWithClaims(tokenString, claims, keys.Keyfunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if claims.Purpose != purpose {
+		return nil, ErrWrongPurpose
+	}
+	return claims, nil
+}
@@ -0,0 +1,77 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// Authenticator data flags
+const (
+	flagUserPresent    byte = 0x01
+	flagUserVerified   byte = 0x04
+	flagBackupEligible byte = 0x08
+	flagBackupState    byte = 0x10
+	flagAttestedData   byte = 0x40
+	flagExtensionData  byte = 0x80
+)
+
+var errMalformedAuthData = errors.New("webauthn: malformed authenticator data")
+
+// authenticatorData is the binary structure signed by the authenticator
+type authenticatorData struct {
+	RPIDHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	// Only present during registration
+	AAGUID       []byte
+	CredentialID []byte
+	PublicKey    []byte
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errMalformedAuthData
+	}
+	ad := &authenticatorData{
+		RPIDHash:  data[:32],
+		Flags:     data[32],
+		SignCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+
+	if ad.Flags&flagAttestedData != 0 {
+		if len(rest) < 18 {
+			return nil, errMalformedAuthData
+		}
+		ad.AAGUID = rest[:16]
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, errMalformedAuthData
+		}
+		ad.CredentialID = rest[:idLength]
+		rest = rest[idLength:]
+
+		// The COSE key has no length prefix, decoding it tells where it ends
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, err
+		}
+		ad.PublicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if ad.Flags&flagExtensionData != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, err
+		}
+		rest = rest[n:]
+	}
+
+	if len(rest) != 0 {
+		return nil, errMalformedAuthData
+	}
+	return ad, nil
+}
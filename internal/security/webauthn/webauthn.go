@@ -0,0 +1,337 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+// Ceremonies a challenge can be issued for
+const (
+	CeremonyRegistration   = "registration"
+	CeremonyAuthentication = "authentication"
+)
+
+var (
+	ErrInvalidClientData = errors.New("webauthn: invalid client data")
+	ErrChallengeMismatch = errors.New("webauthn: challenge mismatch")
+	ErrOriginNotAllowed  = errors.New("webauthn: origin not allowed")
+	ErrRPIDMismatch      = errors.New("webauthn: relying party ID mismatch")
+	ErrUserNotPresent    = errors.New("webauthn: user presence not asserted")
+	ErrUserNotVerified   = errors.New("webauthn: user verification required")
+	ErrInvalidSignature  = errors.New("webauthn: invalid signature")
+	ErrSignCount         = errors.New("webauthn: signature counter did not increase, the authenticator may be cloned")
+)
+
+// RelyingParty identifies this service to the authenticator
+type RelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// UserEntity identifies the account a credential is created for
+type UserEntity struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+// CredentialParameter lists one accepted public key algorithm
+type CredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+// CredentialDescriptor references an existing credential
+type CredentialDescriptor struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Transports []string `json:"transports,omitempty"`
+}
+
+// AuthenticatorSelection states the requirements on the authenticator
+type AuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// CreationOptions is passed to navigator.credentials.create()
+type CreationOptions struct {
+	Challenge              string                 `json:"challenge"`
+	RP                     RelyingParty           `json:"rp"`
+	User                   UserEntity             `json:"user"`
+	PubKeyCredParams       []CredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                  `json:"timeout"`
+	ExcludeCredentials     []CredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection AuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                 `json:"attestation"`
+}
+
+// RequestOptions is passed to navigator.credentials.get()
+type RequestOptions struct {
+	Challenge        string                 `json:"challenge"`
+	Timeout          int64                  `json:"timeout"`
+	RPID             string                 `json:"rpId"`
+	AllowCredentials []CredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                 `json:"userVerification"`
+}
+
+// CredentialResponse is the JSON form of a PublicKeyCredential (PublicKeyCredential.toJSON()).
+// Binary fields are base64url encoded.
+type CredentialResponse struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string   `json:"clientDataJSON"`
+		AttestationObject string   `json:"attestationObject,omitempty"`
+		AuthenticatorData string   `json:"authenticatorData,omitempty"`
+		Signature         string   `json:"signature,omitempty"`
+		UserHandle        string   `json:"userHandle,omitempty"`
+		Transports        []string `json:"transports,omitempty"`
+	} `json:"response"`
+}
+
+// Credential is a verified new credential, ready to be stored
+type Credential struct {
+	ID             string
+	PublicKey      []byte // PKIX DER
+	Algorithm      int64
+	SignCount      uint32
+	AAGUID         string
+	BackupEligible bool
+}
+
+// clientData is the JSON the browser signs together with the authenticator data
+type clientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// Decode reads a base64url value, tolerating padding
+func Decode(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
+
+// Encode writes a base64url value without padding, as WebAuthn does
+func Encode(value []byte) string {
+	return base64.RawURLEncoding.EncodeToString(value)
+}
+
+// NewChallenge returns 32 random bytes, base64url encoded
+func NewChallenge() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return Encode(b), nil
+}
+
+// UserHandle returns the user.id sent to authenticators, the raw bytes of the user's UUID
+func UserHandle(userID string) (string, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return "", err
+	}
+	return Encode(id[:]), nil
+}
+
+// NewCreationOptions builds the options of a registration ceremony.
+// Existing credentials are excluded so the same authenticator is not registered twice.
+func NewCreationOptions(challenge string, user UserEntity, exclude []CredentialDescriptor) CreationOptions {
+	cfg := config.GetConfig()
+
+	return CreationOptions{
+		Challenge: challenge,
+		RP:        RelyingParty{ID: cfg.WebAuthn.RPID, Name: cfg.WebAuthn.RPName},
+		User:      user,
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgES256},
+			{Type: "public-key", Alg: AlgEdDSA},
+			{Type: "public-key", Alg: AlgRS256},
+		},
+		Timeout:            cfg.WebAuthn.Timeout.Milliseconds(),
+		ExcludeCredentials: exclude,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "preferred",
+		},
+		Attestation: "none",
+	}
+}
+
+// NewRequestOptions builds the options of an authentication ceremony.
+// An empty allow list lets the authenticator offer any discoverable credential (passkey).
+func NewRequestOptions(challenge string, allow []CredentialDescriptor, userVerification string) RequestOptions {
+	cfg := config.GetConfig()
+
+	return RequestOptions{
+		Challenge:        challenge,
+		Timeout:          cfg.WebAuthn.Timeout.Milliseconds(),
+		RPID:             cfg.WebAuthn.RPID,
+		AllowCredentials: allow,
+		UserVerification: userVerification,
+	}
+}
+
+// Challenge returns the challenge a response was created for, so the caller can look it up and consume it
+func Challenge(resp CredentialResponse) (string, error) {
+	raw, err := Decode(resp.Response.ClientDataJSON)
+	if err != nil {
+		return "", ErrInvalidClientData
+	}
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil || cd.Challenge == "" {
+		return "", ErrInvalidClientData
+	}
+	return cd.Challenge, nil
+}
+
+// verifyClientData checks the ceremony type, challenge and origin, and returns the SHA-256 of the raw client data
+func verifyClientData(encoded, ceremonyType, challenge string) ([]byte, error) {
+	raw, err := Decode(encoded)
+	if err != nil {
+		return nil, ErrInvalidClientData
+	}
+	var cd clientData
+	if err := json.Unmarshal(raw, &cd); err != nil {
+		return nil, ErrInvalidClientData
+	}
+	if cd.Type != ceremonyType {
+		return nil, ErrInvalidClientData
+	}
+	if cd.Challenge != challenge {
+		return nil, ErrChallengeMismatch
+	}
+
+	allowed := false
+	for _, origin := range config.GetConfig().WebAuthn.Origins {
+		if cd.Origin == origin {
+			allowed = true
+			break
+		}
+	}
+	if !allowed || cd.CrossOrigin {
+		return nil, ErrOriginNotAllowed
+	}
+
+	sum := sha256.Sum256(raw)
+	return sum[:], nil
+}
+
+// verifyAuthenticatorData checks the RP ID hash and the presence and verification flags
+func verifyAuthenticatorData(ad *authenticatorData, requireUserVerification bool) error {
+	rpIDHash := sha256.Sum256([]byte(config.GetConfig().WebAuthn.RPID))
+	if !bytes.Equal(ad.RPIDHash, rpIDHash[:]) {
+		return ErrRPIDMismatch
+	}
+	if ad.Flags&flagUserPresent == 0 {
+		return ErrUserNotPresent
+	}
+	if requireUserVerification && ad.Flags&flagUserVerified == 0 {
+		return ErrUserNotVerified
+	}
+	return nil
+}
+
+// VerifyRegistration validates the response to navigator.credentials.create() for the challenge.
+// Attestation statements are not verified, the service asks for "none" attestation and trusts the
+// authenticator data like any relying party that does not restrict authenticator models.
+func VerifyRegistration(resp CredentialResponse, challenge string) (*Credential, error) {
+	if _, err := verifyClientData(resp.Response.ClientDataJSON, "webauthn.create", challenge); err != nil {
+		return nil, err
+	}
+
+	raw, err := Decode(resp.Response.AttestationObject)
+	if err != nil {
+		return nil, errors.New("webauthn: invalid attestation object")
+	}
+	v, n, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	object, ok := v.(map[interface{}]interface{})
+	if !ok || n != len(raw) {
+		return nil, errors.New("webauthn: invalid attestation object")
+	}
+	authDataBytes, ok := object["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("webauthn: invalid attestation object")
+	}
+
+	ad, err := parseAuthenticatorData(authDataBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyAuthenticatorData(ad, false); err != nil {
+		return nil, err
+	}
+	if ad.CredentialID == nil {
+		return nil, errMalformedAuthData
+	}
+
+	key, alg, err := parseCOSEKey(ad.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aaguid, _ := uuid.FromBytes(ad.AAGUID)
+	return &Credential{
+		ID:             Encode(ad.CredentialID),
+		PublicKey:      der,
+		Algorithm:      alg,
+		SignCount:      ad.SignCount,
+		AAGUID:         aaguid.String(),
+		BackupEligible: ad.Flags&flagBackupEligible != 0,
+	}, nil
+}
+
+// VerifyAssertion validates the response to navigator.credentials.get() against a stored credential
+// and returns the new signature counter. A counter that does not increase is treated as a cloned authenticator,
+// unless the authenticator does not implement counters at all (both values zero).
+func VerifyAssertion(resp CredentialResponse, challenge string, publicKey []byte, alg int64, storedSignCount uint32, requireUserVerification bool) (uint32, error) {
+	clientDataHash, err := verifyClientData(resp.Response.ClientDataJSON, "webauthn.get", challenge)
+	if err != nil {
+		return 0, err
+	}
+
+	authDataBytes, err := Decode(resp.Response.AuthenticatorData)
+	if err != nil {
+		return 0, errMalformedAuthData
+	}
+	ad, err := parseAuthenticatorData(authDataBytes)
+	if err != nil {
+		return 0, err
+	}
+	if err := verifyAuthenticatorData(ad, requireUserVerification); err != nil {
+		return 0, err
+	}
+
+	signature, err := Decode(resp.Response.Signature)
+	if err != nil {
+		return 0, ErrInvalidSignature
+	}
+	signed := append(append([]byte{}, authDataBytes...), clientDataHash...)
+	if err := verifySignature(publicKey, alg, signed, signature); err != nil {
+		return 0, err
+	}
+
+	if (ad.SignCount != 0 || storedSignCount != 0) && ad.SignCount <= storedSignCount {
+		return 0, ErrSignCount
+	}
+	return ad.SignCount, nil
+}
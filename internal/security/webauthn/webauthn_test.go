@@ -0,0 +1,497 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("WEBAUTHN_RP_ID", testRPID)
+	os.Setenv("WEBAUTHN_ORIGINS", testOrigin)
+	config.GetConfig()
+	os.Exit(m.Run())
+}
+
+// cborPair and cborMap keep map keys in order, so the encoded bytes are predictable
+type cborPair struct {
+	key, value interface{}
+}
+
+type cborMap []cborPair
+
+func cborHead(major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return []byte{major<<5 | byte(n)}
+	case n <= 0xff:
+		return []byte{major<<5 | 24, byte(n)}
+	case n <= 0xffff:
+		b := []byte{major<<5 | 25, 0, 0}
+		binary.BigEndian.PutUint16(b[1:], uint16(n))
+		return b
+	default:
+		b := []byte{major<<5 | 26, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(b[1:], uint32(n))
+		return b
+	}
+}
+
+func encodeCBOR(v interface{}) []byte {
+	switch v := v.(type) {
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case int:
+		return encodeCBOR(int64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []interface{}:
+		out := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			out = append(out, encodeCBOR(item)...)
+		}
+		return out
+	case cborMap:
+		out := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			out = append(out, encodeCBOR(pair.key)...)
+			out = append(out, encodeCBOR(pair.value)...)
+		}
+		return out
+	}
+	panic("encodeCBOR: unsupported type")
+}
+
+// softAuthenticator is an in-memory authenticator holding one credential
+type softAuthenticator struct {
+	alg    int64
+	credID []byte
+	ecKey  *ecdsa.PrivateKey
+	edKey  ed25519.PrivateKey
+}
+
+func newSoftAuthenticator(t *testing.T, alg int64) *softAuthenticator {
+	t.Helper()
+	a := &softAuthenticator{alg: alg, credID: make([]byte, 16)}
+	if _, err := rand.Read(a.credID); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	switch alg {
+	case AlgES256:
+		a.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, a.edKey, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		t.Fatalf("unsupported algorithm %d", alg)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func (a *softAuthenticator) coseKey() []byte {
+	if a.alg == AlgES256 {
+		x := make([]byte, 32)
+		y := make([]byte, 32)
+		a.ecKey.X.FillBytes(x)
+		a.ecKey.Y.FillBytes(y)
+		return encodeCBOR(cborMap{
+			{coseKeyType, coseKeyTypeEC2},
+			{coseAlgorithm, AlgES256},
+			{coseCurve, coseCurveP256},
+			{coseX, x},
+			{coseY, y},
+		})
+	}
+	return encodeCBOR(cborMap{
+		{coseKeyType, coseKeyTypeOKP},
+		{coseAlgorithm, AlgEdDSA},
+		{coseCurve, coseCurveEd25519},
+		{coseX, []byte(a.edKey.Public().(ed25519.PublicKey))},
+	})
+}
+
+func (a *softAuthenticator) sign(t *testing.T, data []byte) []byte {
+	t.Helper()
+	if a.alg == AlgEdDSA {
+		return ed25519.Sign(a.edKey, data)
+	}
+	digest := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, a.ecKey, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig
+}
+
+func rpIDHash(rpID string) []byte {
+	sum := sha256.Sum256([]byte(rpID))
+	return sum[:]
+}
+
+func authData(rpHash []byte, flags byte, signCount uint32, attested []byte) []byte {
+	out := append([]byte{}, rpHash...)
+	out = append(out, flags)
+	out = binary.BigEndian.AppendUint32(out, signCount)
+	return append(out, attested...)
+}
+
+func clientDataJSON(t *testing.T, ceremonyType, challenge, origin string) []byte {
+	t.Helper()
+	raw, err := json.Marshal(clientData{Type: ceremonyType, Challenge: challenge, Origin: origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+// ceremony holds the parts of a response a test can tamper with before it is built
+type ceremony struct {
+	ceremonyType string
+	challenge    string
+	origin       string
+	rpHash       []byte
+	flags        byte
+	signCount    uint32
+}
+
+func newCeremony(ceremonyType string, signCount uint32) ceremony {
+	return ceremony{
+		ceremonyType: ceremonyType,
+		challenge:    "challenge",
+		origin:       testOrigin,
+		rpHash:       rpIDHash(testRPID),
+		flags:        flagUserPresent | flagUserVerified,
+		signCount:    signCount,
+	}
+}
+
+func (a *softAuthenticator) register(t *testing.T, c ceremony) CredentialResponse {
+	t.Helper()
+	attested := make([]byte, 16) // zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credID)))
+	attested = append(attested, a.credID...)
+	attested = append(attested, a.coseKey()...)
+
+	object := encodeCBOR(cborMap{
+		{"fmt", "none"},
+		{"attStmt", cborMap{}},
+		{"authData", authData(c.rpHash, c.flags|flagAttestedData, c.signCount, attested)},
+	})
+
+	var resp CredentialResponse
+	resp.ID = Encode(a.credID)
+	resp.RawID = resp.ID
+	resp.Type = "public-key"
+	resp.Response.ClientDataJSON = Encode(clientDataJSON(t, c.ceremonyType, c.challenge, c.origin))
+	resp.Response.AttestationObject = Encode(object)
+	return resp
+}
+
+func (a *softAuthenticator) assert(t *testing.T, c ceremony) CredentialResponse {
+	t.Helper()
+	cd := clientDataJSON(t, c.ceremonyType, c.challenge, c.origin)
+	ad := authData(c.rpHash, c.flags, c.signCount, nil)
+	cdHash := sha256.Sum256(cd)
+
+	var resp CredentialResponse
+	resp.ID = Encode(a.credID)
+	resp.RawID = resp.ID
+	resp.Type = "public-key"
+	resp.Response.ClientDataJSON = Encode(cd)
+	resp.Response.AuthenticatorData = Encode(ad)
+	resp.Response.Signature = Encode(a.sign(t, append(append([]byte{}, ad...), cdHash[:]...)))
+	return resp
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		alg  int64
+	}{
+		{"ES256", AlgES256},
+		{"EdDSA", AlgEdDSA},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newSoftAuthenticator(t, tc.alg)
+
+			cred, err := VerifyRegistration(a.register(t, newCeremony("webauthn.create", 0)), "challenge")
+			if err != nil {
+				t.Fatalf("VerifyRegistration: %v", err)
+			}
+			if cred.ID != Encode(a.credID) || cred.Algorithm != tc.alg || cred.SignCount != 0 {
+				t.Fatalf("unexpected credential %+v", cred)
+			}
+
+			signCount, err := VerifyAssertion(a.assert(t, newCeremony("webauthn.get", 1)), "challenge", cred.PublicKey, cred.Algorithm, 0, true)
+			if err != nil {
+				t.Fatalf("VerifyAssertion: %v", err)
+			}
+			if signCount != 1 {
+				t.Fatalf("sign count = %d, want 1", signCount)
+			}
+
+			// Authenticators without counters always send zero
+			if _, err := VerifyAssertion(a.assert(t, newCeremony("webauthn.get", 0)), "challenge", cred.PublicKey, cred.Algorithm, 0, true); err != nil {
+				t.Fatalf("VerifyAssertion without counter: %v", err)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*ceremony)
+		want   error
+	}{
+		{"wrong origin", func(c *ceremony) { c.origin = "https://evil.example" }, ErrOriginNotAllowed},
+		{"wrong RP ID hash", func(c *ceremony) { c.rpHash = rpIDHash("evil.example") }, ErrRPIDMismatch},
+		{"user not present", func(c *ceremony) { c.flags &^= flagUserPresent }, ErrUserNotPresent},
+		{"wrong challenge", func(c *ceremony) { c.challenge = "other" }, ErrChallengeMismatch},
+		{"wrong ceremony type", func(c *ceremony) { c.ceremonyType = "webauthn.get" }, ErrInvalidClientData},
+	}
+	a := newSoftAuthenticator(t, AlgES256)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newCeremony("webauthn.create", 0)
+			tc.modify(&c)
+			if _, err := VerifyRegistration(a.register(t, c), "challenge"); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionRejects(t *testing.T) {
+	tests := []struct {
+		name       string
+		modify     func(*ceremony)
+		storedSign uint32
+		requireUV  bool
+		want       error
+	}{
+		{"wrong origin", func(c *ceremony) { c.origin = "https://evil.example" }, 0, false, ErrOriginNotAllowed},
+		{"wrong RP ID hash", func(c *ceremony) { c.rpHash = rpIDHash("evil.example") }, 0, false, ErrRPIDMismatch},
+		{"user not present", func(c *ceremony) { c.flags &^= flagUserPresent }, 0, false, ErrUserNotPresent},
+		{"user not verified", func(c *ceremony) { c.flags &^= flagUserVerified }, 0, true, ErrUserNotVerified},
+		{"sign count repeated", func(c *ceremony) { c.signCount = 5 }, 5, false, ErrSignCount},
+		{"sign count regressed", func(c *ceremony) { c.signCount = 4 }, 5, false, ErrSignCount},
+		{"counter dropped to zero", func(c *ceremony) { c.signCount = 0 }, 5, false, ErrSignCount},
+		{"wrong challenge", func(c *ceremony) { c.challenge = "other" }, 0, false, ErrChallengeMismatch},
+		{"wrong ceremony type", func(c *ceremony) { c.ceremonyType = "webauthn.create" }, 0, false, ErrInvalidClientData},
+	}
+	for _, alg := range []struct {
+		name string
+		alg  int64
+	}{
+		{"ES256", AlgES256},
+		{"EdDSA", AlgEdDSA},
+	} {
+		t.Run(alg.name, func(t *testing.T) {
+			a := newSoftAuthenticator(t, alg.alg)
+			cred, err := VerifyRegistration(a.register(t, newCeremony("webauthn.create", 0)), "challenge")
+			if err != nil {
+				t.Fatalf("VerifyRegistration: %v", err)
+			}
+			for _, tc := range tests {
+				t.Run(tc.name, func(t *testing.T) {
+					c := newCeremony("webauthn.get", 1)
+					tc.modify(&c)
+					_, err := VerifyAssertion(a.assert(t, c), "challenge", cred.PublicKey, cred.Algorithm, tc.storedSign, tc.requireUV)
+					if !errors.Is(err, tc.want) {
+						t.Fatalf("err = %v, want %v", err, tc.want)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionRejectsForeignSignature(t *testing.T) {
+	a := newSoftAuthenticator(t, AlgES256)
+	cred, err := VerifyRegistration(a.register(t, newCeremony("webauthn.create", 0)), "challenge")
+	if err != nil {
+		t.Fatalf("VerifyRegistration: %v", err)
+	}
+
+	// Another authenticator signing the same data must not pass for the registered credential
+	other := newSoftAuthenticator(t, AlgES256)
+	resp := other.assert(t, newCeremony("webauthn.get", 1))
+	if _, err := VerifyAssertion(resp, "challenge", cred.PublicKey, cred.Algorithm, 0, false); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	// A tampered authenticator data breaks the signature too
+	resp = a.assert(t, newCeremony("webauthn.get", 1))
+	ad, _ := Decode(resp.Response.AuthenticatorData)
+	ad[36]++
+	resp.Response.AuthenticatorData = Encode(ad)
+	if _, err := VerifyAssertion(resp, "challenge", cred.PublicKey, cred.Algorithm, 0, false); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("err = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifyRegistrationRejectsMalformedAttestation(t *testing.T) {
+	a := newSoftAuthenticator(t, AlgES256)
+	valid := a.register(t, newCeremony("webauthn.create", 0))
+	object, _ := Decode(valid.Response.AttestationObject)
+
+	longID := make([]byte, 1024)
+	attested := binary.BigEndian.AppendUint16(make([]byte, 16), uint16(len(longID)))
+	attested = append(append(attested, longID...), a.coseKey()...)
+
+	tests := []struct {
+		name   string
+		object []byte
+	}{
+		{"trailing data", append(append([]byte{}, object...), 0x00)},
+		{"truncated", object[:len(object)-1]},
+		{"not a map", encodeCBOR([]interface{}{int64(1)})},
+		{"missing authData", encodeCBOR(cborMap{{"fmt", "none"}})},
+		{"authData too short", encodeCBOR(cborMap{{"authData", []byte{1, 2, 3}}})},
+		{"credential ID too long", encodeCBOR(cborMap{{"authData", authData(rpIDHash(testRPID), flagUserPresent|flagAttestedData, 0, attested)}})},
+		{"no attested credential", encodeCBOR(cborMap{{"authData", authData(rpIDHash(testRPID), flagUserPresent, 0, nil)}})},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := valid
+			resp.Response.AttestationObject = Encode(tc.object)
+			if _, err := VerifyRegistration(resp, "challenge"); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestDecodeCBOR(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+		want  interface{}
+	}{
+		{"small integer", []byte{0x0a}, int64(10)},
+		{"negative integer", []byte{0x38, 0x63}, int64(-100)},
+		{"uint16", []byte{0x19, 0x01, 0x00}, int64(256)},
+		{"byte string", []byte{0x43, 1, 2, 3}, []byte{1, 2, 3}},
+		{"text string", []byte{0x63, 'f', 'm', 't'}, "fmt"},
+		{"true", []byte{0xf5}, true},
+		{"null", []byte{0xf6}, nil},
+		{"tagged", []byte{0xc2, 0x41, 0x01}, []byte{1}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, n, err := decodeCBOR(tc.input)
+			if err != nil {
+				t.Fatalf("decodeCBOR: %v", err)
+			}
+			if n != len(tc.input) {
+				t.Fatalf("used %d bytes, want %d", n, len(tc.input))
+			}
+			if b, ok := tc.want.([]byte); ok {
+				if !bytes.Equal(got.([]byte), b) {
+					t.Fatalf("got %v, want %v", got, b)
+				}
+				return
+			}
+			if got != tc.want {
+				t.Fatalf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	m, _, err := decodeCBOR(encodeCBOR(cborMap{{"a", int64(1)}, {int64(-1), []interface{}{"x"}}}))
+	if err != nil {
+		t.Fatalf("decodeCBOR map: %v", err)
+	}
+	if mm := m.(map[interface{}]interface{}); mm["a"] != int64(1) || mm[int64(-1)].([]interface{})[0] != "x" {
+		t.Fatalf("unexpected map %v", m)
+	}
+}
+
+func TestDecodeCBORRejects(t *testing.T) {
+	deep := bytes.Repeat([]byte{0x81}, maxCBORDepth+2)
+	deep = append(deep, 0x00)
+
+	tests := []struct {
+		name  string
+		input []byte
+		want  error
+	}{
+		{"empty", nil, errCBORTruncated},
+		{"truncated head", []byte{0x19, 0x01}, errCBORTruncated},
+		{"truncated byte string", []byte{0x45, 1, 2}, errCBORTruncated},
+		{"oversized byte string", []byte{0x5b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, errCBORTruncated},
+		{"oversized array", []byte{0x9b, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00}, errCBORTruncated},
+		{"oversized map", []byte{0xba, 0xff, 0xff, 0xff, 0xff}, errCBORTruncated},
+		{"indefinite length", []byte{0x5f, 0x41, 0x01, 0xff}, errCBORUnsupported},
+		{"float", []byte{0xf9, 0x3c, 0x00}, errCBORUnsupported},
+		{"integer overflow", []byte{0x1b, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, errCBORUnsupported},
+		{"byte string map key", []byte{0xa1, 0x41, 0x01, 0x01}, errCBORUnsupported},
+		{"nesting too deep", deep, errCBORTooDeep},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := decodeCBOR(tc.input); !errors.Is(err, tc.want) {
+				t.Fatalf("err = %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseCOSEKeyRejects(t *testing.T) {
+	a := newSoftAuthenticator(t, AlgES256)
+	valid := a.coseKey()
+
+	offCurve := encodeCBOR(cborMap{
+		{coseKeyType, coseKeyTypeEC2},
+		{coseAlgorithm, AlgES256},
+		{coseCurve, coseCurveP256},
+		{coseX, bytes.Repeat([]byte{1}, 32)},
+		{coseY, bytes.Repeat([]byte{2}, 32)},
+	})
+	wrongAlg := encodeCBOR(cborMap{
+		{coseKeyType, coseKeyTypeEC2},
+		{coseAlgorithm, AlgEdDSA},
+		{coseCurve, coseCurveP256},
+	})
+
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"trailing data", append(append([]byte{}, valid...), 0x00)},
+		{"point not on curve", offCurve},
+		{"key type and algorithm mismatch", wrongAlg},
+		{"not a map", encodeCBOR(int64(1))},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, _, err := parseCOSEKey(tc.input); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
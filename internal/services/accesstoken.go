@@ -0,0 +1,134 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// PersonalAccessTokenPrefix starts every personal access token, so secret scanners can find leaked ones
+// and the auth middleware can tell them apart from JWTs
+const PersonalAccessTokenPrefix = "afp_pat_"
+
+// personalAccessTokenDisplayLength is how much of a token is kept in clear text to recognise it in listings
+const personalAccessTokenDisplayLength = len(PersonalAccessTokenPrefix) + 8
+
+var ErrInvalidPersonalAccessToken = errors.New("invalid, expired or revoked personal access token")
+
+// CreatePersonalAccessToken stores a new token for the user and returns it with the raw token, only its hash is stored
+func CreatePersonalAccessToken(db *sql.DB, userID, name string, permissions []string, ttl time.Duration) (*models.NewPersonalAccessToken, error) {
+	random, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	token := PersonalAccessTokenPrefix + random
+
+	created := &models.NewPersonalAccessToken{
+		PersonalAccessToken: models.PersonalAccessToken{
+			UserID:      userID,
+			Name:        name,
+			Prefix:      token[:personalAccessTokenDisplayLength],
+			Permissions: permissions,
+		},
+		Token: token,
+	}
+	err = db.QueryRow(`
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, token_prefix, permissions, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6), NOW())
+		RETURNING id, expires_at, created_at`,
+		userID, name, utils.HashToken(token), created.Prefix, pq.Array(permissions), ttl.Seconds(),
+	).Scan(&created.ID, &created.ExpiresAt, &created.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// ListPersonalAccessTokens returns the tokens of a user that are not revoked, newest first. Expired tokens are listed until revoked.
+func ListPersonalAccessTokens(db *sql.DB, userID string) ([]models.PersonalAccessToken, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, name, token_prefix, permissions, expires_at, last_used_at, COALESCE(last_used_ip, ''), created_at
+		FROM personal_access_tokens
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.PersonalAccessToken{}
+	for rows.Next() {
+		var token models.PersonalAccessToken
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(
+			&token.ID, &token.UserID, &token.Name, &token.Prefix, pq.Array(&token.Permissions),
+			&token.ExpiresAt, &lastUsedAt, &token.LastUsedIP, &token.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		if lastUsedAt.Valid {
+			token.LastUsedAt = &lastUsedAt.Time
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+// RevokePersonalAccessToken revokes a token of a user.
+// It returns false if the token does not exist, belongs to someone else or is already revoked.
+func RevokePersonalAccessToken(db *sql.DB, userID, tokenID string) (bool, error) {
+	if _, err := uuid.Parse(tokenID); err != nil {
+		return false, nil
+	}
+
+	res, err := db.Exec(`
+		UPDATE personal_access_tokens SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		tokenID, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// AuthenticatePersonalAccessToken looks up a valid token and returns its owner and permissions.
+// It also records when and from where the token was last used, at most once per minute.
+func AuthenticatePersonalAccessToken(db *sql.DB, token, ipAddress string) (*models.PersonalAccessToken, error) {
+	pat, err := LookupPersonalAccessToken(db, token)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.Exec(`
+		UPDATE personal_access_tokens SET last_used_at = NOW(), last_used_ip = $2
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute' OR last_used_ip IS DISTINCT FROM $2)`,
+		pat.ID, ipAddress,
+	)
+	return pat, err
+}
+
+// LookupPersonalAccessToken returns a token that is neither expired nor revoked without recording a use
+func LookupPersonalAccessToken(db *sql.DB, token string) (*models.PersonalAccessToken, error) {
+	var pat models.PersonalAccessToken
+	err := db.QueryRow(`
+		SELECT id, user_id, name, token_prefix, permissions, expires_at, created_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()`,
+		utils.HashToken(token),
+	).Scan(&pat.ID, &pat.UserID, &pat.Name, &pat.Prefix, pq.Array(&pat.Permissions), &pat.ExpiresAt, &pat.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidPersonalAccessToken
+	} else if err != nil {
+		return nil, err
+	}
+	return &pat, nil
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+var ErrInvalidMagicLink = errors.New("invalid or expired magic link")
+
+// IssueMagicLink replaces the unused magic links of a user with a new one.
+// It returns the raw link token and the 6-digit code, only their hashes are stored.
+func IssueMagicLink(db *sql.DB, userID string) (string, string, error) {
+	cfg := config.GetConfig()
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", "", err
+	}
+	code := fmt.Sprintf("%06d", n.Int64())
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM magic_link_tokens WHERE user_id = $1 AND used_at IS NULL`, userID)
+	if err != nil {
+		return "", "", err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO magic_link_tokens (user_id, token_hash, code_hash, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, 0, NOW() + make_interval(secs => $4), NOW())`,
+		userID, utils.HashToken(token), magicLinkCodeHash(userID, code), cfg.MagicLink.TTL.Seconds(),
+	)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, code, tx.Commit()
+}
+
+// ConsumeMagicLinkToken uses up a link token and returns the user it was issued for
+func ConsumeMagicLinkToken(db *sql.DB, token string) (string, error) {
+	var userID string
+	err := db.QueryRow(`
+		UPDATE magic_link_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id`,
+		utils.HashToken(token),
+	).Scan(&userID)
+	if err == sql.ErrNoRows {
+		return "", ErrInvalidMagicLink
+	}
+	return userID, err
+}
+
+// ConsumeMagicLinkCode checks a 6-digit code against the pending magic link of a user and uses it up.
+// Every wrong code counts, after MAGIC_LINK_MAX_CODE_ATTEMPTS the link is burnt.
+func ConsumeMagicLinkCode(db *sql.DB, userID, code string) error {
+	cfg := config.GetConfig()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var id, codeHash string
+	var attempts int
+	err = tx.QueryRow(`
+		SELECT id, code_hash, attempts FROM magic_link_tokens
+		WHERE user_id = $1 AND used_at IS NULL AND expires_at > NOW()
+		FOR UPDATE`,
+		userID,
+	).Scan(&id, &codeHash, &attempts)
+	if err == sql.ErrNoRows {
+		return ErrInvalidMagicLink
+	} else if err != nil {
+		return err
+	}
+	if attempts >= cfg.MagicLink.MaxCodeAttempts {
+		return ErrInvalidMagicLink
+	}
+
+	if subtle.ConstantTimeCompare([]byte(magicLinkCodeHash(userID, code)), []byte(codeHash)) != 1 {
+		if _, err := tx.Exec(`UPDATE magic_link_tokens SET attempts = attempts + 1 WHERE id = $1`, id); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		return ErrInvalidMagicLink
+	}
+
+	if _, err := tx.Exec(`UPDATE magic_link_tokens SET used_at = NOW() WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// magicLinkCodeHash binds a code to its user, so equal codes of different users hash differently
+func magicLinkCodeHash(userID, code string) string {
+	return utils.HashToken(userID + ":" + code)
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/totp"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+// recoveryCodeCount is the number of recovery codes handed out at once
+const recoveryCodeCount = 10
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication enrollment was not started")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+// GetMFAStatus returns whether the user has a confirmed second factor and how many recovery codes are left.
+func GetMFAStatus(db *sql.DB, userID string) (models.MFAStatus, error) {
+	var status models.MFAStatus
+	var confirmedAt sql.NullTime
+	err := db.QueryRow(`SELECT confirmed_at FROM user_mfa WHERE user_id = $1`, userID).Scan(&confirmedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return status, err
+	}
+	if confirmedAt.Valid {
+		status.Enabled = true
+		status.ConfirmedAt = &confirmedAt.Time
+	}
+
+	err = db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = $1 AND used_at IS NULL),
+			(SELECT COUNT(*) FROM webauthn_credentials WHERE user_id = $1)`,
+		userID,
+	).Scan(&status.RecoveryCodesRemaining, &status.Passkeys)
+	return status, err
+}
+
+// MFAMethods returns the second factors the user can present on login.
+// An empty list means the password alone completes the login.
+func MFAMethods(db *sql.DB, userID string) ([]string, error) {
+	var totpEnabled, hasPasskeys bool
+	err := db.QueryRow(`
+		SELECT
+			EXISTS(SELECT 1 FROM user_mfa WHERE user_id = $1 AND confirmed_at IS NOT NULL),
+			EXISTS(SELECT 1 FROM webauthn_credentials WHERE user_id = $1)`,
+		userID,
+	).Scan(&totpEnabled, &hasPasskeys)
+	if err != nil {
+		return nil, err
+	}
+
+	methods := []string{}
+	if totpEnabled {
+		methods = append(methods, "totp", "recovery_code")
+	}
+	if hasPasskeys {
+		methods = append(methods, "webauthn")
+	}
+	return methods, nil
+}
+
+// StartTOTPEnrollment generates a new TOTP secret for the user. Any unconfirmed enrollment is replaced.
+// MFA is not enforced until the enrollment is confirmed with a first code.
+func StartTOTPEnrollment(db *sql.DB, userID, account string) (models.MFAEnrollment, error) {
+	cfg := config.GetConfig()
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return models.MFAEnrollment{}, err
+	}
+
+	res, err := db.Exec(`
+		INSERT INTO user_mfa (user_id, totp_secret, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET totp_secret = EXCLUDED.totp_secret, last_used_step = NULL, created_at = NOW(), updated_at = NOW()
+		WHERE user_mfa.confirmed_at IS NULL`,
+		userID, secret,
+	)
+	if err != nil {
+		return models.MFAEnrollment{}, err
+	}
+	if rows, err := res.RowsAffected(); err != nil {
+		return models.MFAEnrollment{}, err
+	} else if rows == 0 {
+		return models.MFAEnrollment{}, ErrMFAAlreadyEnabled
+	}
+
+	return models.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(cfg.MFA.Issuer, account, secret),
+	}, nil
+}
+
+// ConfirmTOTPEnrollment enables MFA once the user proves the authenticator works,
+// and returns a fresh set of recovery codes. The codes are only ever shown here.
+func ConfirmTOTPEnrollment(db *sql.DB, userID, code string) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var secret string
+	var confirmed bool
+	err = tx.QueryRow(`SELECT totp_secret, confirmed_at IS NOT NULL FROM user_mfa WHERE user_id = $1 FOR UPDATE`, userID).
+		Scan(&secret, &confirmed)
+	if err == sql.ErrNoRows {
+		return nil, ErrMFANotEnrolled
+	} else if err != nil {
+		return nil, err
+	}
+	if confirmed {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	_, err = tx.Exec(`UPDATE user_mfa SET confirmed_at = NOW(), last_used_step = $2, updated_at = NOW() WHERE user_id = $1`, userID, step)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return codes, tx.Commit()
+}
+
+// VerifyMFACode checks a TOTP code or, failing that, a recovery code of the user.
+// A TOTP code is accepted once, and a recovery code is burnt when it matches.
+func VerifyMFACode(db *sql.DB, userID, code string) (bool, error) {
+	var secret string
+	err := db.QueryRow(`SELECT totp_secret FROM user_mfa WHERE user_id = $1 AND confirmed_at IS NOT NULL`, userID).Scan(&secret)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		// Only move forward, so a code that was seen already cannot be replayed
+		res, err := db.Exec(`
+			UPDATE user_mfa SET last_used_step = $2, updated_at = NOW()
+			WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)`,
+			userID, step,
+		)
+		if err != nil {
+			return false, err
+		}
+		rows, err := res.RowsAffected()
+		return rows == 1, err
+	}
+
+	res, err := db.Exec(`
+		UPDATE mfa_recovery_codes SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`,
+		userID, utils.HashToken(normalizeRecoveryCode(code)),
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows > 0, err
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of a user with a new set.
+func RegenerateRecoveryCodes(db *sql.DB, userID string) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	codes, err := replaceRecoveryCodes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return codes, tx.Commit()
+}
+
+// DisableMFA removes the second factor and the recovery codes of a user.
+// It reports whether the user had MFA set up at all.
+func DisableMFA(db *sql.DB, userID string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	return rows > 0, err
+}
+
+// replaceRecoveryCodes deletes the old recovery codes and stores the hashes of new ones.
+func replaceRecoveryCodes(tx *sql.Tx, userID string) ([]string, error) {
+	if _, err := tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		_, err = tx.Exec(`
+			INSERT INTO mfa_recovery_codes (user_id, code_hash, created_at)
+			VALUES ($1, $2, NOW())`,
+			userID, utils.HashToken(normalizeRecoveryCode(code)),
+		)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode makes recovery codes case and separator insensitive before hashing
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
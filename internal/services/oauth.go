@@ -0,0 +1,250 @@
+package services
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/oidc"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+var (
+	ErrOAuthClientNotFound = errors.New("oauth client not found")
+	// ErrInvalidClient is returned when a client cannot be authenticated
+	ErrInvalidClient = errors.New("invalid client credentials")
+	// ErrInvalidGrant is returned for unknown, expired, used or mismatched authorization codes
+	ErrInvalidGrant = errors.New("invalid or expired authorization code")
+)
+
+// oauthClientRolesColumn selects the role names of the client aliased c as an array
+const oauthClientRolesColumn = `ARRAY(SELECT r.name FROM oauth_client_roles cr JOIN roles r ON cr.role_id = r.id WHERE cr.client_id = c.id ORDER BY r.name)`
+
+// AuthorizationCode is what a user approved at the authorization endpoint, bound to the client and the PKCE challenge
+type AuthorizationCode struct {
+	ClientID      string
+	UserID        string
+	SessionID     string
+	RedirectURI   string
+	Scope         string
+	CodeChallenge string
+	Nonce         string
+	// AuthTime is when the session behind the code was created
+	AuthTime time.Time
+}
+
+// CreateOAuthClient registers a client. Confidential clients get a secret that is returned here once and stored hashed.
+// createdBy is empty when a service account registers the client.
+func CreateOAuthClient(db *sql.DB, req models.OAuthClientRequest, createdBy string) (*models.OAuthClientCredentials, error) {
+	var secret string
+	var secretHash sql.NullString
+	if !req.Public {
+		var err error
+		secret, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			return nil, err
+		}
+		secretHash = sql.NullString{String: utils.HashToken(secret), Valid: true}
+	}
+
+	credentials := &models.OAuthClientCredentials{
+		OAuthClient: models.OAuthClient{
+			Name:          req.Name,
+			RedirectURIs:  req.RedirectURIs,
+			AllowedScopes: req.AllowedScopes,
+			GrantTypes:    req.GrantTypes,
+			Roles:         []string{},
+			Public:        req.Public,
+		},
+		ClientSecret: secret,
+	}
+	err := db.QueryRow(`
+		INSERT INTO oauth_clients (name, secret_hash, redirect_uris, allowed_scopes, grant_types, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		RETURNING id, created_at`,
+		req.Name, secretHash, pq.Array(req.RedirectURIs), pq.Array(req.AllowedScopes), pq.Array(req.GrantTypes),
+		sql.NullString{String: createdBy, Valid: createdBy != ""},
+	).Scan(&credentials.ClientID, &credentials.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// ListOAuthClients returns all registered clients, oldest first
+func ListOAuthClients(db *sql.DB) ([]models.OAuthClient, error) {
+	rows, err := db.Query(`
+		SELECT c.id, c.name, c.secret_hash IS NULL, c.redirect_uris, c.allowed_scopes, c.grant_types, ` + oauthClientRolesColumn + `, c.created_at
+		FROM oauth_clients c
+		ORDER BY c.created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	clients := []models.OAuthClient{}
+	for rows.Next() {
+		var client models.OAuthClient
+		if err := rows.Scan(&client.ClientID, &client.Name, &client.Public, pq.Array(&client.RedirectURIs), pq.Array(&client.AllowedScopes), pq.Array(&client.GrantTypes), pq.Array(&client.Roles), &client.CreatedAt); err != nil {
+			return nil, err
+		}
+		clients = append(clients, client)
+	}
+	return clients, rows.Err()
+}
+
+// GetOAuthClient loads a registered client
+func GetOAuthClient(db *sql.DB, clientID string) (*models.OAuthClient, error) {
+	client, _, err := getOAuthClient(db, clientID)
+	return client, err
+}
+
+// DeleteOAuthClient removes a client together with its pending authorization codes
+func DeleteOAuthClient(db *sql.DB, clientID string) error {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return ErrOAuthClientNotFound
+	}
+
+	result, err := db.Exec(`DELETE FROM oauth_clients WHERE id = $1`, clientID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrOAuthClientNotFound
+	}
+	return nil
+}
+
+// AuthenticateOAuthClient checks the credentials a client presents at the token endpoint.
+// Public clients must not send a secret, confidential clients must send theirs.
+func AuthenticateOAuthClient(db *sql.DB, clientID, secret string) (*models.OAuthClient, error) {
+	client, secretHash, err := getOAuthClient(db, clientID)
+	if err == ErrOAuthClientNotFound {
+		return nil, ErrInvalidClient
+	} else if err != nil {
+		return nil, err
+	}
+
+	if client.Public {
+		if secret != "" {
+			return nil, ErrInvalidClient
+		}
+		return client, nil
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(secretHash)) != 1 {
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+// AssignOAuthClientRole gives a client the permissions of a role, assigning a role twice is not an error
+func AssignOAuthClientRole(db *sql.DB, clientID, roleID, assignedBy string) error {
+	_, err := db.Exec(`
+		INSERT INTO oauth_client_roles (client_id, role_id, assigned_by, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (client_id, role_id) DO NOTHING`,
+		clientID, roleID, sql.NullString{String: assignedBy, Valid: assignedBy != ""},
+	)
+	return err
+}
+
+// RemoveOAuthClientRole takes a role away from a client and reports whether it had the role
+func RemoveOAuthClientRole(db *sql.DB, clientID, roleID string) (bool, error) {
+	result, err := db.Exec(`DELETE FROM oauth_client_roles WHERE client_id = $1 AND role_id = $2`, clientID, roleID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	return n > 0, nil
+}
+
+// IsServiceAccountActive reports whether a client still exists and may use the client_credentials grant.
+// Tokens of deleted service accounts stop working with it.
+func IsServiceAccountActive(db *sql.DB, clientID string) (bool, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return false, nil
+	}
+
+	var active bool
+	err := db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM oauth_clients WHERE id = $1 AND $2 = ANY(grant_types))`,
+		clientID, oidc.GrantClientCredentials,
+	).Scan(&active)
+	return active, err
+}
+
+// getOAuthClient loads a client and the hash of its secret, empty for public clients
+func getOAuthClient(db *sql.DB, clientID string) (*models.OAuthClient, string, error) {
+	// Client IDs are UUIDs, anything else cannot match and would only make Postgres complain
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, "", ErrOAuthClientNotFound
+	}
+
+	var client models.OAuthClient
+	var secretHash sql.NullString
+	err := db.QueryRow(`
+		SELECT c.id, c.name, c.secret_hash, c.redirect_uris, c.allowed_scopes, c.grant_types, `+oauthClientRolesColumn+`, c.created_at
+		FROM oauth_clients c
+		WHERE c.id = $1`,
+		clientID,
+	).Scan(&client.ClientID, &client.Name, &secretHash, pq.Array(&client.RedirectURIs), pq.Array(&client.AllowedScopes), pq.Array(&client.GrantTypes), pq.Array(&client.Roles), &client.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, "", ErrOAuthClientNotFound
+	} else if err != nil {
+		return nil, "", err
+	}
+	client.Public = !secretHash.Valid
+	return &client, secretHash.String, nil
+}
+
+// IssueAuthorizationCode stores an approved authorization request and returns the code for the client, only its hash is stored
+func IssueAuthorizationCode(db *sql.DB, code AuthorizationCode) (string, error) {
+	cfg := config.GetConfig()
+
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO oauth_authorization_codes (code_hash, client_id, user_id, session_id, redirect_uri, scope, code_challenge, nonce, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW() + make_interval(secs => $9), NOW())`,
+		utils.HashToken(raw), code.ClientID, code.UserID, code.SessionID, code.RedirectURI, code.Scope, code.CodeChallenge,
+		sql.NullString{String: code.Nonce, Valid: code.Nonce != ""}, cfg.OAuth.CodeTTL.Seconds(),
+	)
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// ConsumeAuthorizationCode uses up an authorization code issued to the client for the redirect URI.
+// A code works once, whoever presents it first, so a leaked code is useless after the client traded it.
+func ConsumeAuthorizationCode(db *sql.DB, code, clientID, redirectURI string) (*AuthorizationCode, error) {
+	var ac AuthorizationCode
+	var nonce sql.NullString
+	err := db.QueryRow(`
+		UPDATE oauth_authorization_codes c SET used_at = NOW()
+		FROM sessions s
+		WHERE c.code_hash = $1 AND c.used_at IS NULL AND c.expires_at > NOW() AND s.id = c.session_id
+		RETURNING c.client_id, c.user_id, c.session_id, c.redirect_uri, c.scope, c.code_challenge, c.nonce, s.created_at`,
+		utils.HashToken(code),
+	).Scan(&ac.ClientID, &ac.UserID, &ac.SessionID, &ac.RedirectURI, &ac.Scope, &ac.CodeChallenge, &nonce, &ac.AuthTime)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidGrant
+	} else if err != nil {
+		return nil, err
+	}
+	ac.Nonce = nonce.String
+
+	// The code is burnt either way, a mismatch means it was stolen or replayed by another client
+	if ac.ClientID != clientID || ac.RedirectURI != redirectURI {
+		return nil, ErrInvalidGrant
+	}
+	return &ac, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/password"
+)
+
+// CheckNewPassword runs the password policy for a password about to be set.
+// userID is empty for accounts that do not exist yet, they have no password history.
+// It returns the broken rules, an empty list means the password is accepted.
+func CheckNewPassword(db *sql.DB, userID, pw string, subject password.Subject) ([]password.Violation, error) {
+	policy := password.GetPolicy()
+
+	// History checks hash the password once per stored hash, only run them for otherwise valid passwords
+	violations := policy.Check(pw, subject)
+	if len(violations) > 0 || userID == "" || policy.History <= 0 {
+		return violations, nil
+	}
+
+	hashes, err := passwordHistory(db, userID, policy.History)
+	if err != nil {
+		return nil, err
+	}
+	return append(violations, policy.CheckHistory(pw, hashes)...), nil
+}
+
+// passwordHistory returns the current password hash of a user followed by the previous ones, newest first
+func passwordHistory(db *sql.DB, userID string, limit int) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT password_hash FROM (
+			SELECT password_hash, NOW() AS created_at, 0 AS current FROM users WHERE id = $1
+			UNION ALL
+			SELECT password_hash, created_at, 1 AS current FROM password_history WHERE user_id = $1
+		) h
+		ORDER BY current, created_at DESC
+		LIMIT $2`,
+		userID, limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The newest history entry usually is the current hash
+	var hashes []string
+	seen := make(map[string]bool)
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, err
+		}
+		if !seen[hash] {
+			seen[hash] = true
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, rows.Err()
+}
+
+// RecordPasswordHistory remembers a newly set password hash and forgets the ones beyond PASSWORD_HISTORY
+func RecordPasswordHistory(db *sql.DB, userID, hash string) error {
+	history := password.GetPolicy().History
+
+	if history > 0 {
+		_, err := db.Exec(`INSERT INTO password_history (user_id, password_hash, created_at) VALUES ($1, $2, NOW())`, userID, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := db.Exec(`
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2
+		)`,
+		userID, history,
+	)
+	return err
+}
+
+// MustChangePassword reports whether an admin forced the user to change their password before doing anything else
+func MustChangePassword(db *sql.DB, userID string) (bool, error) {
+	var mustChange bool
+	err := db.QueryRow(`SELECT must_change_password FROM users WHERE id = $1`, userID).Scan(&mustChange)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return mustChange, err
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// PasswordResetUser is the account a valid reset token belongs to
+type PasswordResetUser struct {
+	ID       string
+	Username string
+	Email    string
+}
+
+// IssuePasswordResetToken revokes the unused reset tokens of a user and returns a new one.
+// Only the hash of the token is stored, it expires after PASSWORD_RESET_TTL.
+func IssuePasswordResetToken(db *sql.DB, userID string) (string, error) {
+	cfg := config.GetConfig()
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE password_reset_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, NOW() + make_interval(secs => $3), NOW())`,
+		userID, utils.HashToken(token), cfg.Password.PasswordResetTTL.Seconds(),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return token, tx.Commit()
+}
+
+// LookupPasswordResetToken returns the verified user a usable reset token belongs to, without using it up
+func LookupPasswordResetToken(db *sql.DB, token string) (*PasswordResetUser, error) {
+	var user PasswordResetUser
+	err := db.QueryRow(`
+		SELECT u.id, u.username, u.email
+		FROM password_reset_tokens t
+		JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = $1 AND t.used_at IS NULL AND t.revoked_at IS NULL AND t.expires_at > NOW()
+		AND u.email_verified = TRUE`,
+		utils.HashToken(token),
+	).Scan(&user.ID, &user.Username, &user.Email)
+	if err == sql.ErrNoRows {
+		return nil, ErrInvalidResetToken
+	}
+	return &user, err
+}
+
+// ResetPassword uses up a reset token and sets the new password hash in one transaction,
+// so a token can only ever change the password once. Other tokens of the user are revoked too.
+func ResetPassword(db *sql.DB, token, userID, passwordHash string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND user_id = $2 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()`,
+		utils.HashToken(token), userID,
+	)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return ErrInvalidResetToken
+	}
+
+	_, err = tx.Exec(`
+		UPDATE password_reset_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL AND revoked_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users SET password_hash = $1, must_change_password = FALSE, updated_at = NOW()
+		WHERE id = $2`,
+		passwordHash, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+)
+
+var (
+	ErrPermissionNotFound = errors.New("permission not found")
+	ErrPermissionExists   = errors.New("a permission with this name already exists")
+)
+
+// GetPermission returns a permission by ID
+func GetPermission(db *sql.DB, permissionID string) (*models.Permission, error) {
+	if _, err := uuid.Parse(permissionID); err != nil {
+		return nil, ErrPermissionNotFound
+	}
+
+	var perm models.Permission
+	err := db.QueryRow(`
+		SELECT id, name, COALESCE(description, ''), resource, action
+		FROM permissions WHERE id = $1`,
+		permissionID,
+	).Scan(&perm.ID, &perm.Name, &perm.Description, &perm.Resource, &perm.Action)
+	if err == sql.ErrNoRows {
+		return nil, ErrPermissionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	return &perm, nil
+}
+
+// UpdatePermission replaces the name, description, resource and action of a permission
+func UpdatePermission(db *sql.DB, permissionID string, req models.PermissionRequest) (*models.Permission, error) {
+	if _, err := uuid.Parse(permissionID); err != nil {
+		return nil, ErrPermissionNotFound
+	}
+
+	var perm models.Permission
+	err := db.QueryRow(`
+		UPDATE permissions
+		SET name = $2, description = $3, resource = $4, action = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, name, COALESCE(description, ''), resource, action`,
+		permissionID, req.Name, req.Description, req.Resource, req.Action,
+	).Scan(&perm.ID, &perm.Name, &perm.Description, &perm.Resource, &perm.Action)
+	if err == sql.ErrNoRows {
+		return nil, ErrPermissionNotFound
+	}
+	if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+		return nil, ErrPermissionExists
+	} else if err != nil {
+		return nil, err
+	}
+	return &perm, nil
+}
+
+// DeletePermission deletes a permission, the roles that granted it lose it
+func DeletePermission(db *sql.DB, permissionID string) error {
+	if _, err := uuid.Parse(permissionID); err != nil {
+		return ErrPermissionNotFound
+	}
+
+	res, err := db.Exec(`DELETE FROM permissions WHERE id = $1`, permissionID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return ErrPermissionNotFound
+	}
+	return nil
+}
+
+// ListPermissionUsage returns every permission with the roles that grant it directly and the number of users holding it,
+// also through inherited roles
+func ListPermissionUsage(db *sql.DB) ([]models.PermissionUsage, error) {
+	rows, err := db.Query(`
+		SELECT p.id, p.name, COALESCE(p.description, ''), p.resource, p.action,
+			COALESCE(ARRAY_AGG(r.id::text ORDER BY r.name) FILTER (WHERE r.id IS NOT NULL), '{}'),
+			COALESCE(ARRAY_AGG(r.name ORDER BY r.name) FILTER (WHERE r.id IS NOT NULL), '{}'),
+			(SELECT COUNT(DISTINCT ur.user_id)
+				FROM effective_role_permissions urp
+				JOIN user_roles ur ON ur.role_id = urp.role_id
+				WHERE urp.permission_id = p.id)
+		FROM permissions p
+		LEFT JOIN role_permissions rp ON rp.permission_id = p.id
+		LEFT JOIN roles r ON r.id = rp.role_id
+		GROUP BY p.id
+		ORDER BY p.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := []models.PermissionUsage{}
+	for rows.Next() {
+		var u models.PermissionUsage
+		var roleIDs, roleNames []string
+		if err := rows.Scan(
+			&u.ID, &u.Name, &u.Description, &u.Resource, &u.Action,
+			pq.Array(&roleIDs), pq.Array(&roleNames), &u.UserCount,
+		); err != nil {
+			return nil, err
+		}
+		u.Roles = make([]models.RoleSummary, len(roleIDs))
+		for i := range roleIDs {
+			u.Roles[i] = models.RoleSummary{ID: roleIDs[i], Name: roleNames[i]}
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"database/sql"
+	"time"
+)
+
+// RevokeTokenID puts the jti of a signed token on the deny list. The token stays valid by its signature,
+// so the entry is kept for ttl, until the token expires anyway.
+func RevokeTokenID(db *sql.DB, jti string, ttl time.Duration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Entries of expired tokens are no longer needed
+	if _, err := tx.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		INSERT INTO revoked_tokens (jti, expires_at, revoked_at)
+		VALUES ($1, NOW() + make_interval(secs => $2), NOW())
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, ttl.Seconds(),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// IsTokenRevoked reports whether the token with the jti was revoked
+func IsTokenRevoked(db *sql.DB, jti string) (bool, error) {
+	if jti == "" {
+		return false, nil
+	}
+
+	var revoked bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
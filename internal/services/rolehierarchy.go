@@ -0,0 +1,204 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+)
+
+var ErrRoleCycle = errors.New("the role would inherit from itself")
+
+// roleAncestors selects the IDs of a role ($1) and every role it inherits from. UNION stops at roles already seen.
+const roleAncestors = `
+	WITH RECURSIVE ancestors(id) AS (
+		SELECT $1::uuid
+		UNION
+		SELECT rp.parent_role_id FROM role_parents rp JOIN ancestors a ON rp.role_id = a.id
+	)`
+
+// AddRoleParent lets a role inherit the permissions of a parent role. It returns false if the role already had the parent,
+// and ErrRoleCycle if the parent inherits from the role.
+func AddRoleParent(db *sql.DB, roleID, parentRoleID string) (bool, error) {
+	if roleID == parentRoleID {
+		return false, ErrRoleCycle
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// Two concurrent changes could each pass the check and form a cycle together
+	if _, err := tx.Exec(`LOCK TABLE role_parents IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+		return false, err
+	}
+
+	var cycle bool
+	err = tx.QueryRow(roleAncestors+`
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`,
+		parentRoleID, roleID,
+	).Scan(&cycle)
+	if err != nil {
+		return false, err
+	}
+	if cycle {
+		return false, ErrRoleCycle
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO role_parents (role_id, parent_role_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (role_id, parent_role_id) DO NOTHING`,
+		roleID, parentRoleID,
+	)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected > 0, tx.Commit()
+}
+
+// RemoveRoleParent stops a role from inheriting from a parent role. It returns false if the role did not have the parent.
+func RemoveRoleParent(db *sql.DB, roleID, parentRoleID string) (bool, error) {
+	if _, err := uuid.Parse(parentRoleID); err != nil {
+		return false, nil
+	}
+
+	res, err := db.Exec(`DELETE FROM role_parents WHERE role_id = $1 AND parent_role_id = $2`, roleID, parentRoleID)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// ListEffectiveRolePermissions returns the permissions a role grants, including those inherited from parent roles
+func ListEffectiveRolePermissions(db *sql.DB, roleID string) ([]models.Permission, error) {
+	return queryPermissions(db, `
+		SELECT DISTINCT p.id, p.name, COALESCE(p.description, ''), p.resource, p.action
+		FROM effective_role_permissions rp
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.name`,
+		roleID,
+	)
+}
+
+// ResolveRolePermissions returns the tree of a role and its parent roles with the permissions each one grants directly
+func ResolveRolePermissions(db *sql.DB, roleID string) (*models.ResolvedRolePermissions, error) {
+	if _, err := uuid.Parse(roleID); err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	// Step 1: The role and every role it inherits from
+	rows, err := db.Query(roleAncestors+`
+		SELECT r.id, r.name FROM roles r JOIN ancestors a ON a.id = r.id`,
+		roleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]string)
+	var ids []string
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names[id] = name
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if _, ok := names[roleID]; !ok {
+		return nil, ErrRoleNotFound
+	}
+
+	// Step 2: Their parents and direct permissions
+	parents, err := groupByRole(db, `
+		SELECT rp.role_id, rp.parent_role_id::text
+		FROM role_parents rp
+		JOIN roles r ON r.id = rp.parent_role_id
+		WHERE rp.role_id = ANY($1::uuid[])
+		ORDER BY r.name`,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := groupByRole(db, `
+		SELECT rp.role_id, p.name
+		FROM role_permissions rp
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE rp.role_id = ANY($1::uuid[])
+		ORDER BY p.name`,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 3: Build the tree, a role reached twice through different parents shows up under both
+	var build func(id string, path map[string]bool) models.RolePermissionTree
+	build = func(id string, path map[string]bool) models.RolePermissionTree {
+		node := models.RolePermissionTree{
+			RoleSummary: models.RoleSummary{ID: id, Name: names[id]},
+			Permissions: permissions[id],
+			Parents:     []models.RolePermissionTree{},
+		}
+		if node.Permissions == nil {
+			node.Permissions = []string{}
+		}
+		path[id] = true
+		for _, parentID := range parents[id] {
+			if !path[parentID] {
+				node.Parents = append(node.Parents, build(parentID, path))
+			}
+		}
+		delete(path, id)
+		return node
+	}
+
+	effective := make(map[string]bool)
+	for _, perms := range permissions {
+		for _, perm := range perms {
+			effective[perm] = true
+		}
+	}
+	resolved := &models.ResolvedRolePermissions{
+		Tree:                 build(roleID, make(map[string]bool)),
+		EffectivePermissions: make([]string, 0, len(effective)),
+	}
+	for perm := range effective {
+		resolved.EffectivePermissions = append(resolved.EffectivePermissions, perm)
+	}
+	sort.Strings(resolved.EffectivePermissions)
+	return resolved, nil
+}
+
+// groupByRole runs a query that selects a role ID and a value for the roles in $1 and groups the values by role
+func groupByRole(db *sql.DB, query string, roleIDs []string) (map[string][]string, error) {
+	rows, err := db.Query(query, pq.Array(roleIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grouped := make(map[string][]string)
+	for rows.Next() {
+		var roleID, value string
+		if err := rows.Scan(&roleID, &value); err != nil {
+			return nil, err
+		}
+		grouped[roleID] = append(grouped[roleID], value)
+	}
+	return grouped, rows.Err()
+}
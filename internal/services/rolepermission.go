@@ -0,0 +1,108 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+)
+
+// SystemAdminRole is the role that holds every permission, it can never lose one
+const SystemAdminRole = "system_admin"
+
+var ErrRoleNotFound = errors.New("role not found")
+
+// GetRoleName returns the name of a role
+func GetRoleName(db *sql.DB, roleID string) (string, error) {
+	if _, err := uuid.Parse(roleID); err != nil {
+		return "", ErrRoleNotFound
+	}
+
+	var name string
+	err := db.QueryRow(`SELECT name FROM roles WHERE id = $1`, roleID).Scan(&name)
+	if err == sql.ErrNoRows {
+		return "", ErrRoleNotFound
+	}
+	return name, err
+}
+
+// ListRolePermissions returns the permissions a role grants, ordered by name
+func ListRolePermissions(db *sql.DB, roleID string) ([]models.Permission, error) {
+	return queryPermissions(db, `
+		SELECT p.id, p.name, COALESCE(p.description, ''), p.resource, p.action
+		FROM role_permissions rp
+		JOIN permissions p ON rp.permission_id = p.id
+		WHERE rp.role_id = $1
+		ORDER BY p.name`,
+		roleID,
+	)
+}
+
+// FindPermissions returns the permissions with the given IDs, IDs that do not exist are left out
+func FindPermissions(db *sql.DB, permissionIDs []string) ([]models.Permission, error) {
+	var valid []string
+	for _, id := range permissionIDs {
+		if _, err := uuid.Parse(id); err == nil {
+			valid = append(valid, id)
+		}
+	}
+	if len(valid) == 0 {
+		return []models.Permission{}, nil
+	}
+
+	return queryPermissions(db, `
+		SELECT id, name, COALESCE(description, ''), resource, action
+		FROM permissions
+		WHERE id = ANY($1::uuid[])
+		ORDER BY name`,
+		pq.Array(valid),
+	)
+}
+
+// GrantRolePermissions adds permissions to a role and returns how many it did not have yet
+func GrantRolePermissions(db *sql.DB, roleID string, permissionIDs []string) (int64, error) {
+	res, err := db.Exec(`
+		INSERT INTO role_permissions (role_id, permission_id, created_at)
+		SELECT $1, unnest($2::uuid[]), NOW()
+		ON CONFLICT (role_id, permission_id) DO NOTHING`,
+		roleID, pq.Array(permissionIDs),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// RevokeRolePermissions removes permissions from a role and returns how many it had
+func RevokeRolePermissions(db *sql.DB, roleID string, permissionIDs []string) (int64, error) {
+	res, err := db.Exec(`
+		DELETE FROM role_permissions
+		WHERE role_id = $1 AND permission_id = ANY($2::uuid[])`,
+		roleID, pq.Array(permissionIDs),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// queryPermissions runs a query that selects id, name, description, resource and action of permissions
+func queryPermissions(db *sql.DB, query string, args ...interface{}) ([]models.Permission, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []models.Permission{}
+	for rows.Next() {
+		var perm models.Permission
+		if err := rows.Scan(&perm.ID, &perm.Name, &perm.Description, &perm.Resource, &perm.Action); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, perm)
+	}
+	return permissions, rows.Err()
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"database/sql"
+
+	"github.com/google/uuid"
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+)
+
+// CreateSession stores a new session for the user and returns its ID.
+// The session lives as long as a refresh token, and every refresh extends it.
+func CreateSession(db *sql.DB, userID, userAgent, ipAddress string) (string, error) {
+	cfg := config.GetConfig()
+
+	var sessionID string
+	err := db.QueryRow(`
+		INSERT INTO sessions (user_id, user_agent, ip_address, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, NOW(), NOW(), NOW() + make_interval(secs => $4))
+		RETURNING id`,
+		userID, userAgent, ipAddress, cfg.JWT.RefreshExpiry.Seconds(),
+	).Scan(&sessionID)
+	return sessionID, err
+}
+
+// ExtendSession pushes the expiry of an active session forward after a token refresh.
+func ExtendSession(db *sql.DB, sessionID string) error {
+	cfg := config.GetConfig()
+
+	_, err := db.Exec(`
+		UPDATE sessions SET expires_at = NOW() + make_interval(secs => $2), last_seen_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL`,
+		sessionID, cfg.JWT.RefreshExpiry.Seconds(),
+	)
+	return err
+}
+
+// IsSessionActive reports whether the session exists for the user and was neither revoked nor expired.
+// It also records the last time the session was seen, at most once per minute.
+func IsSessionActive(db *sql.DB, sessionID, userID string) (bool, error) {
+	var active bool
+	err := db.QueryRow(`
+		SELECT revoked_at IS NULL AND expires_at > NOW()
+		FROM sessions
+		WHERE id = $1 AND user_id = $2`,
+		sessionID, userID,
+	).Scan(&active)
+	if err == sql.ErrNoRows {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if active {
+		_, err = db.Exec(`
+			UPDATE sessions SET last_seen_at = NOW()
+			WHERE id = $1 AND last_seen_at < NOW() - INTERVAL '1 minute'`,
+			sessionID,
+		)
+	}
+	return active, err
+}
+
+// ListSessions returns the active sessions of a user, newest first.
+// The session matching currentSessionID is flagged as the current one.
+func ListSessions(db *sql.DB, userID, currentSessionID string) ([]models.Session, error) {
+	rows, err := db.Query(`
+		SELECT id, user_id, COALESCE(user_agent, ''), COALESCE(ip_address, ''), created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(
+			&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+			&session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt,
+		); err != nil {
+			return nil, err
+		}
+		session.Current = session.ID == currentSessionID
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession revokes a single session of a user together with its refresh tokens.
+// It returns false if the session does not exist, belongs to someone else or is already revoked.
+func RevokeSession(db *sql.DB, userID, sessionID string) (bool, error) {
+	// An ID that is not a UUID cannot match a session
+	if _, err := uuid.Parse(sessionID); err != nil {
+		return false, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return false, nil
+	}
+
+	_, err = tx.Exec(`UPDATE refresh_tokens SET revoked_at = NOW() WHERE session_id = $1 AND revoked_at IS NULL`, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// RevokeAllSessions revokes every session of a user except exceptSessionID (which may be empty)
+// and returns the number of sessions that were revoked.
+func RevokeAllSessions(db *sql.DB, userID, exceptSessionID string) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE sessions SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND id::text <> $2`,
+		userID, exceptSessionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	revoked, _ := res.RowsAffected()
+
+	_, err = tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL AND (session_id IS NULL OR session_id::text <> $2)`,
+		userID, exceptSessionID,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return revoked, tx.Commit()
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+// GetUser loads a user without the secrets kept in the users table
+func GetUser(db *sql.DB, userID string) (*models.User, error) {
+	var user models.User
+	var firstName, lastName sql.NullString
+	var emailVerified, active sql.NullBool
+	err := db.QueryRow(`
+		SELECT id, username, first_name, last_name, email, email_verified, user_type, active, created_at, updated_at
+		FROM users
+		WHERE id = $1`,
+		userID,
+	).Scan(&user.ID, &user.Username, &firstName, &lastName, &user.Email, &emailVerified, &user.UserType, &active, &user.CreatedAt, &user.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	user.FirstName = firstName.String
+	user.LastName = lastName.String
+	user.EmailVerified = emailVerified.Bool
+	// Rows from before the column default count as active
+	user.Active = !active.Valid || active.Bool
+	return &user, nil
+}
+
+// UserRoles returns the names of the roles assigned to a user
+func UserRoles(db *sql.DB, userID string) ([]string, error) {
+	rows, err := db.Query(`
+		SELECT r.name
+		FROM user_roles ur
+		JOIN roles r ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []string{}
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
@@ -0,0 +1,270 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+)
+
+// builtinRoles are the roles of the promotion ladder, most privileged first.
+// The first of them a user holds is the primary role, kept in users.user_type for the login tokens.
+var builtinRoles = []string{SystemAdminRole, "admin", "moderator", "user"}
+
+// primaryRoleOrder sorts the roles of a user joined as ur and r by the ladder, then by assignment, with the ladder as $2
+const primaryRoleOrder = `ORDER BY COALESCE(array_position($2::text[], r.name::text), 2147483647), ur.created_at, r.name`
+
+var (
+	ErrLastRole        = errors.New("a user must keep at least one role")
+	ErrLastSystemAdmin = errors.New("the last system admin cannot lose the role")
+)
+
+// IsBuiltinRole reports whether a role is one of the promotion ladder
+func IsBuiltinRole(name string) bool {
+	for _, role := range builtinRoles {
+		if role == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetUserPrimaryRole returns the primary role of a user derived from the assigned roles, empty if there are none
+func GetUserPrimaryRole(db *sql.DB, userID string) (string, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return "", ErrUserNotFound
+	}
+
+	var exists bool
+	if err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", ErrUserNotFound
+	}
+
+	var role string
+	err := db.QueryRow(`
+		SELECT r.name
+		FROM user_roles ur
+		JOIN roles r ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		`+primaryRoleOrder+`
+		LIMIT 1`,
+		userID, pq.Array(builtinRoles),
+	).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// ListUserRoles returns the roles assigned to a user, ordered by name
+func ListUserRoles(db *sql.DB, userID string) ([]models.RoleSummary, error) {
+	rows, err := db.Query(`
+		SELECT r.id, r.name
+		FROM user_roles ur
+		JOIN roles r ON ur.role_id = r.id
+		WHERE ur.user_id = $1
+		ORDER BY r.name`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []models.RoleSummary{}
+	for rows.Next() {
+		var role models.RoleSummary
+		if err := rows.Scan(&role.ID, &role.Name); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+	return roles, rows.Err()
+}
+
+// AddUserRole assigns a role to a user on top of the roles they have. It returns false if the user already had it.
+func AddUserRole(db *sql.DB, userID, roleID, assignedBy string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO user_roles (user_id, role_id, assigned_by, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, role_id) DO NOTHING`,
+		userID, roleID, assignedBy,
+	)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, _ := res.RowsAffected()
+	if err := syncPrimaryRole(tx, userID); err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, tx.Commit()
+}
+
+// RemoveUserRole takes a role away from a user. It returns false if the user did not have it,
+// ErrLastRole instead of leaving the user without any role and ErrLastSystemAdmin instead of leaving the system without a system admin.
+func RemoveUserRole(db *sql.DB, userID, roleID string) (bool, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	admins, err := lockSystemAdmins(tx)
+	if err != nil {
+		return false, err
+	}
+
+	res, err := tx.Exec(`DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`, userID, roleID)
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return false, nil
+	}
+
+	var remaining int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM user_roles WHERE user_id = $1`, userID).Scan(&remaining); err != nil {
+		return false, err
+	}
+	if remaining == 0 {
+		return false, ErrLastRole
+	}
+	if err := checkSystemAdminLeft(tx, admins); err != nil {
+		return false, err
+	}
+	if err := syncPrimaryRole(tx, userID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// SetUserRole makes a role the only role of a user
+func SetUserRole(db *sql.DB, userID, roleID, assignedBy string) error {
+	return replaceUserRoles(db, userID, roleID, assignedBy, false)
+}
+
+// SetUserBuiltinRole moves a user to another step of the promotion ladder, the roles outside the ladder are kept
+func SetUserBuiltinRole(db *sql.DB, userID, roleID, assignedBy string) error {
+	return replaceUserRoles(db, userID, roleID, assignedBy, true)
+}
+
+// replaceUserRoles removes the roles of a user, only those of the ladder if onlyBuiltin is set, and assigns the role.
+// It returns ErrLastSystemAdmin instead of taking the role from the last system admin.
+func replaceUserRoles(db *sql.DB, userID, roleID, assignedBy string, onlyBuiltin bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	admins, err := lockSystemAdmins(tx)
+	if err != nil {
+		return err
+	}
+
+	if onlyBuiltin {
+		_, err = tx.Exec(`
+			DELETE FROM user_roles ur
+			USING roles r
+			WHERE ur.role_id = r.id AND ur.user_id = $1 AND ur.role_id <> $2 AND r.name = ANY($3::text[])`,
+			userID, roleID, pq.Array(builtinRoles),
+		)
+	} else {
+		_, err = tx.Exec(`DELETE FROM user_roles WHERE user_id = $1 AND role_id <> $2`, userID, roleID)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO user_roles (user_id, role_id, assigned_by, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id, role_id) DO NOTHING`,
+		userID, roleID, assignedBy,
+	)
+	if err != nil {
+		return err
+	}
+	if err := checkSystemAdminLeft(tx, admins); err != nil {
+		return err
+	}
+	if err := syncPrimaryRole(tx, userID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// lockSystemAdmins locks the system admin assignments until the transaction ends and returns how many there are.
+// Concurrent removals run one after the other, so each sees what the previous one left.
+func lockSystemAdmins(tx *sql.Tx) (int, error) {
+	rows, err := tx.Query(`
+		SELECT ur.user_id
+		FROM user_roles ur
+		JOIN roles r ON ur.role_id = r.id
+		WHERE r.name = $1
+		FOR UPDATE OF ur`,
+		SystemAdminRole,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	return count, rows.Err()
+}
+
+// checkSystemAdminLeft returns ErrLastSystemAdmin when the transaction removed the last of the system admins it locked
+func checkSystemAdminLeft(tx *sql.Tx, lockedAdmins int) error {
+	if lockedAdmins == 0 {
+		return nil
+	}
+
+	var exists bool
+	err := tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM user_roles ur
+			JOIN roles r ON ur.role_id = r.id
+			WHERE r.name = $1
+		)`,
+		SystemAdminRole,
+	).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrLastSystemAdmin
+	}
+	return nil
+}
+
+// syncPrimaryRole stores the primary role of a user in users.user_type, which is only a copy of what user_roles says
+func syncPrimaryRole(tx *sql.Tx, userID string) error {
+	_, err := tx.Exec(`
+		UPDATE users SET user_type = COALESCE((
+			SELECT r.name
+			FROM user_roles ur
+			JOIN roles r ON ur.role_id = r.id
+			WHERE ur.user_id = $1
+			`+primaryRoleOrder+`
+			LIMIT 1
+		), user_type), updated_at = NOW()
+		WHERE id = $1`,
+		userID, pq.Array(builtinRoles),
+	)
+	return err
+}
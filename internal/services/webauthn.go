@@ -0,0 +1,204 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+	"github.com/sagorsarker04/Developer-Assignment/internal/models"
+	"github.com/sagorsarker04/Developer-Assignment/internal/security/webauthn"
+	"github.com/sagorsarker04/Developer-Assignment/internal/utils"
+)
+
+var (
+	ErrChallengeNotFound  = errors.New("webauthn challenge not found or expired")
+	ErrCredentialExists   = errors.New("webauthn credential is already registered")
+	ErrCredentialNotFound = errors.New("webauthn credential not found")
+)
+
+// StoredCredential is what an assertion is verified against
+type StoredCredential struct {
+	ID        string
+	UserID    string
+	PublicKey []byte
+	Algorithm int64
+	SignCount uint32
+}
+
+// CreateWebAuthnChallenge stores a new single-use challenge for a ceremony.
+// userID is empty for passwordless logins, where the user is only known from the credential.
+func CreateWebAuthnChallenge(db *sql.DB, userID, ceremony string) (string, error) {
+	cfg := config.GetConfig()
+
+	challenge, err := webauthn.NewChallenge()
+	if err != nil {
+		return "", err
+	}
+
+	// Drop expired challenges while we are here
+	if _, err := db.Exec(`DELETE FROM webauthn_challenges WHERE expires_at < NOW()`); err != nil {
+		return "", err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO webauthn_challenges (user_id, challenge_hash, ceremony, expires_at, created_at)
+		VALUES (NULLIF($1, '')::uuid, $2, $3, NOW() + make_interval(secs => $4), NOW())`,
+		userID, utils.HashToken(challenge), ceremony, cfg.WebAuthn.Timeout.Seconds(),
+	)
+	return challenge, err
+}
+
+// ConsumeWebAuthnChallenge deletes the challenge and returns the user it was issued for (empty when none).
+// A challenge can only ever be consumed once.
+func ConsumeWebAuthnChallenge(db *sql.DB, challenge, ceremony string) (string, error) {
+	var userID sql.NullString
+	var valid bool
+	err := db.QueryRow(`
+		DELETE FROM webauthn_challenges
+		WHERE challenge_hash = $1 AND ceremony = $2
+		RETURNING user_id, expires_at > NOW()`,
+		utils.HashToken(challenge), ceremony,
+	).Scan(&userID, &valid)
+	if err == sql.ErrNoRows {
+		return "", ErrChallengeNotFound
+	} else if err != nil {
+		return "", err
+	}
+	if !valid {
+		return "", ErrChallengeNotFound
+	}
+	return userID.String, nil
+}
+
+// WebAuthnDescriptors returns the credentials of a user in the form used by exclude and allow lists
+func WebAuthnDescriptors(db *sql.DB, userID string) ([]webauthn.CredentialDescriptor, error) {
+	rows, err := db.Query(`SELECT credential_id, COALESCE(transports, '') FROM webauthn_credentials WHERE user_id = $1`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	descriptors := []webauthn.CredentialDescriptor{}
+	for rows.Next() {
+		var id, transports string
+		if err := rows.Scan(&id, &transports); err != nil {
+			return nil, err
+		}
+		descriptors = append(descriptors, webauthn.CredentialDescriptor{
+			Type:       "public-key",
+			ID:         id,
+			Transports: splitTransports(transports),
+		})
+	}
+	return descriptors, rows.Err()
+}
+
+// SaveWebAuthnCredential stores a verified credential for the user
+func SaveWebAuthnCredential(db *sql.DB, userID, name string, credential *webauthn.Credential, transports []string) (string, error) {
+	var id string
+	err := db.QueryRow(`
+		INSERT INTO webauthn_credentials
+			(user_id, credential_id, public_key, algorithm, sign_count, aaguid, transports, backup_eligible, name, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, NOW())
+		ON CONFLICT (credential_id) DO NOTHING
+		RETURNING id`,
+		userID, credential.ID, credential.PublicKey, credential.Algorithm, int64(credential.SignCount),
+		credential.AAGUID, strings.Join(transports, ","), credential.BackupEligible, name,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", ErrCredentialExists
+	}
+	return id, err
+}
+
+// GetWebAuthnCredential loads a credential by the ID the authenticator reports
+func GetWebAuthnCredential(db *sql.DB, credentialID string) (*StoredCredential, error) {
+	var c StoredCredential
+	var signCount int64
+	err := db.QueryRow(`
+		SELECT id, user_id, public_key, algorithm, sign_count
+		FROM webauthn_credentials
+		WHERE credential_id = $1`,
+		credentialID,
+	).Scan(&c.ID, &c.UserID, &c.PublicKey, &c.Algorithm, &signCount)
+	if err == sql.ErrNoRows {
+		return nil, ErrCredentialNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	c.SignCount = uint32(signCount)
+	return &c, nil
+}
+
+// RecordWebAuthnUse stores the new signature counter after a successful assertion.
+// The counter only moves forward, which also catches two concurrent logins with the same assertion.
+func RecordWebAuthnUse(db *sql.DB, id string, oldSignCount, newSignCount uint32) (bool, error) {
+	res, err := db.Exec(`
+		UPDATE webauthn_credentials SET sign_count = $3, last_used_at = NOW()
+		WHERE id = $1 AND sign_count = $2`,
+		id, int64(oldSignCount), int64(newSignCount),
+	)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows == 1, err
+}
+
+// ListWebAuthnCredentials returns the credentials of a user, newest first
+func ListWebAuthnCredentials(db *sql.DB, userID string) ([]models.WebAuthnCredential, error) {
+	rows, err := db.Query(`
+		SELECT id, name, credential_id, COALESCE(transports, ''), backup_eligible, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE user_id = $1
+		ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	credentials := []models.WebAuthnCredential{}
+	for rows.Next() {
+		var c models.WebAuthnCredential
+		var transports string
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(&c.ID, &c.Name, &c.CredentialID, &transports, &c.BackupEligible, &c.CreatedAt, &lastUsedAt); err != nil {
+			return nil, err
+		}
+		c.Transports = splitTransports(transports)
+		if lastUsedAt.Valid {
+			c.LastUsedAt = &lastUsedAt.Time
+		}
+		credentials = append(credentials, c)
+	}
+	return credentials, rows.Err()
+}
+
+// DeleteWebAuthnCredential removes one credential of a user, it reports whether the credential existed
+func DeleteWebAuthnCredential(db *sql.DB, userID, id string) (bool, error) {
+	res, err := db.Exec(`DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := res.RowsAffected()
+	return rows > 0, err
+}
+
+// DeleteAllWebAuthnCredentials removes every credential of a user and returns how many there were
+func DeleteAllWebAuthnCredentials(db *sql.DB, userID string) (int64, error) {
+	res, err := db.Exec(`DELETE FROM webauthn_credentials WHERE user_id = $1`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func splitTransports(transports string) []string {
+	if transports == "" {
+		return []string{}
+	}
+	return strings.Split(transports, ",")
+}
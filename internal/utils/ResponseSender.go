@@ -26,3 +26,14 @@ func ErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 		"data":    nil,
 	})
 }
+
+// ErrorResponseWithData sends an error JSON response carrying details, such as validation errors, in data
+func ErrorResponseWithData(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":  statusCode,
+		"message": message,
+		"data":    data,
+	})
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/sagorsarker04/Developer-Assignment/internal/config"
+)
+
+// ClientIP returns the IP address of the caller.
+// Proxy headers are only honoured when TRUST_PROXY_HEADERS is enabled.
+func ClientIP(r *http.Request) string {
+	cfg := config.GetConfig()
+
+	if cfg.Server.TrustProxyHeaders {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
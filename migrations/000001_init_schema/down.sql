@@ -1,4 +1,23 @@
 -- Drop tables in reverse order
+DROP VIEW IF EXISTS effective_role_permissions;
+DROP TABLE IF EXISTS role_parents;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS personal_access_tokens;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS oauth_client_roles;
+DROP TABLE IF EXISTS oauth_clients;
+DROP TABLE IF EXISTS magic_link_tokens;
+DROP TABLE IF EXISTS password_reset_tokens;
+DROP TABLE IF EXISTS password_history;
+DROP TABLE IF EXISTS rate_limit_buckets;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS webauthn_challenges;
+DROP TABLE IF EXISTS webauthn_credentials;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
+DROP TABLE IF EXISTS signing_keys;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS sessions;
 DROP TABLE IF EXISTS role_permissions;
 DROP TABLE IF EXISTS user_roles;
 DROP TABLE IF EXISTS permissions;
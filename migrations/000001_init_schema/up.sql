@@ -6,20 +6,27 @@ CREATE TABLE IF NOT EXISTS users (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     username VARCHAR(50) UNIQUE NOT NULL,
     email VARCHAR(100) UNIQUE NOT NULL,
-    password_hash VARCHAR(100) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
     first_name VARCHAR(50),
     last_name VARCHAR(50),
     email_verified BOOLEAN DEFAULT FALSE,
-    user_type VARCHAR(20) NOT NULL,
+    user_type VARCHAR(50) NOT NULL,
     verification_token VARCHAR(100),
     reset_token VARCHAR(100),
     token_expiry TIMESTAMP,
     deletion_requested BOOLEAN DEFAULT FALSE,
     active BOOLEAN DEFAULT TRUE,
+    must_change_password BOOLEAN NOT NULL DEFAULT FALSE,
     created_at TIMESTAMP NOT NULL DEFAULT NOW(),
     updated_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 
+-- Bring the users table of older databases up to date, argon2id PHC strings do not fit the bcrypt sized column
+ALTER TABLE users ALTER COLUMN password_hash TYPE VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+-- user_type holds the primary role derived from user_roles, so it must fit any role name
+ALTER TABLE users ALTER COLUMN user_type TYPE VARCHAR(50);
+
 -- Roles table
 CREATE TABLE IF NOT EXISTS roles (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -57,6 +64,231 @@ CREATE TABLE IF NOT EXISTS role_permissions (
     PRIMARY KEY (role_id, permission_id)
 );
 
+-- Parent roles, a role inherits every permission of its parents
+CREATE TABLE IF NOT EXISTS role_parents (
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    parent_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (role_id, parent_role_id),
+    CHECK (role_id <> parent_role_id)
+);
+CREATE INDEX IF NOT EXISTS idx_role_parents_parent_role_id ON role_parents(parent_role_id);
+
+-- Permissions of every role including those inherited from its parents, source_role_id is the role that grants it directly
+CREATE OR REPLACE VIEW effective_role_permissions AS
+WITH RECURSIVE role_ancestors(role_id, ancestor_id) AS (
+    SELECT id, id FROM roles
+    UNION
+    SELECT ra.role_id, rp.parent_role_id
+    FROM role_ancestors ra
+    JOIN role_parents rp ON rp.role_id = ra.ancestor_id
+)
+SELECT ra.role_id, rp.permission_id, ra.ancestor_id AS source_role_id
+FROM role_ancestors ra
+JOIN role_permissions rp ON rp.role_id = ra.ancestor_id;
+
+-- Sessions table (one row per login, checked on every authenticated request)
+CREATE TABLE IF NOT EXISTS sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+
+-- RefreshTokens table (opaque, rotated on every use; one family per login)
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID REFERENCES sessions(id) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    replaced_by UUID REFERENCES refresh_tokens(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+
+-- SigningKeys table (JWT keyring; private_key is NULL for the key configured in the environment)
+CREATE TABLE IF NOT EXISTS signing_keys (
+    kid VARCHAR(100) PRIMARY KEY,
+    algorithm VARCHAR(10) NOT NULL,
+    private_key TEXT,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMP,
+    verify_until TIMESTAMP
+);
+
+-- UserMFA table (TOTP second factor; confirmed_at is NULL until the first code was accepted)
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    totp_secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- MFARecoveryCodes table (one-time codes, only the SHA-256 hash is stored)
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mfa_recovery_codes_user_id ON mfa_recovery_codes(user_id);
+
+-- WebAuthnCredentials table (passkeys and security keys; public_key is PKIX DER)
+CREATE TABLE IF NOT EXISTS webauthn_credentials (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    credential_id VARCHAR(1400) UNIQUE NOT NULL,
+    public_key BYTEA NOT NULL,
+    algorithm INTEGER NOT NULL,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    aaguid UUID,
+    transports TEXT,
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_webauthn_credentials_user_id ON webauthn_credentials(user_id);
+
+-- WebAuthnChallenges table (single-use ceremony challenges; user_id is NULL for passwordless login)
+CREATE TABLE IF NOT EXISTS webauthn_challenges (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    challenge_hash VARCHAR(64) UNIQUE NOT NULL,
+    ceremony VARCHAR(20) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- LoginAttempts table (failure counters per account email and client IP, see LOCKOUT_* settings)
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP
+);
+
+-- RateLimitBuckets table (token buckets shared by all instances when RATE_LIMIT_STORE=postgres)
+CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+    key VARCHAR(400) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Previous password hashes, checked so users cannot reuse a recent password
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history(user_id, created_at);
+
+-- Password reset tokens, only the SHA-256 hash of a token is stored
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
+
+-- Magic link sign-in, the link token and the emailed code are stored hashed
+CREATE TABLE IF NOT EXISTS magic_link_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_magic_link_tokens_user_id ON magic_link_tokens(user_id);
+
+-- OAuth clients, public clients have no secret, confidential ones store the SHA-256 hash of theirs
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    secret_hash VARCHAR(64),
+    redirect_uris TEXT[] NOT NULL DEFAULT '{}',
+    allowed_scopes TEXT[] NOT NULL DEFAULT '{}',
+    grant_types TEXT[] NOT NULL DEFAULT '{authorization_code}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Roles of service accounts (clients with the client_credentials grant), they grant permissions like user_roles
+CREATE TABLE IF NOT EXISTS oauth_client_roles (
+    client_id UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    assigned_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (client_id, role_id)
+);
+
+-- Authorization codes of the OAuth authorization-code flow, bound to a client, session and PKCE challenge
+CREATE TABLE IF NOT EXISTS oauth_authorization_codes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code_hash VARCHAR(64) UNIQUE NOT NULL,
+    client_id UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    session_id UUID NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+    redirect_uri TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    code_challenge VARCHAR(64) NOT NULL,
+    nonce TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_oauth_authorization_codes_user_id ON oauth_authorization_codes(user_id);
+
+-- Personal access tokens users create for scripts, limited to some of their permissions
+CREATE TABLE IF NOT EXISTS personal_access_tokens (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    token_prefix VARCHAR(20) NOT NULL,
+    permissions TEXT[] NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_personal_access_tokens_user_id ON personal_access_tokens(user_id);
+
+-- IDs (jti) of signed tokens revoked before they expire, kept until the token would have expired
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
 -- Insert default roles
 INSERT INTO roles (name, description) VALUES
     ('system_admin', 'Full system access with ability to manage all aspects of the system'),
@@ -78,9 +310,20 @@ INSERT INTO permissions (name, resource, action, description) VALUES
     ('role:update', 'role', 'update', 'Update roles'),
     ('role:delete', 'role', 'delete', 'Delete roles'),
     ('permission:read', 'permission', 'read', 'Read permissions'),
+    ('permission:create', 'permission', 'create', 'Create permissions'),
+    ('permission:update', 'permission', 'update', 'Update permissions'),
+    ('permission:delete', 'permission', 'delete', 'Delete permissions the routes do not require'),
+    ('user:role:manage', 'user', 'role:manage', 'Assign and remove additional roles of users'),
     ('user:promote:admin', 'user', 'promote:admin', 'Promote user to admin'),
     ('user:promote:moderator', 'user', 'promote:moderator', 'Promote user to moderator'),
-    ('user:demote', 'user', 'demote', 'Demote user role');
+    ('user:demote', 'user', 'demote', 'Demote user role'),
+    ('session:revoke:all', 'session', 'revoke:all', 'Revoke the sessions of any user'),
+    ('key:manage', 'key', 'manage', 'List, rotate and retire JWT signing keys'),
+    ('mfa:reset', 'mfa', 'reset', 'Reset the two-factor authentication of any user'),
+    ('lockout:manage', 'lockout', 'manage', 'List and clear login lockouts'),
+    ('password:force_reset', 'password', 'force_reset', 'Force a user to change their password'),
+    ('oauth:client:manage', 'oauth', 'client:manage', 'Register, list and delete OAuth clients'),
+    ('role:permission:manage', 'role', 'permission:manage', 'Grant and revoke the permissions of roles');
 
 -- Assign permissions to roles
 -- System Admin permissions
@@ -96,7 +339,7 @@ SELECT
     (SELECT id FROM roles WHERE name = 'admin'), 
     id 
 FROM permissions
-WHERE name NOT IN ('user:promote:admin');
+WHERE name NOT IN ('user:promote:admin', 'key:manage', 'oauth:client:manage', 'role:permission:manage');
 
 -- Moderator permissions
 INSERT INTO role_permissions (role_id, permission_id)
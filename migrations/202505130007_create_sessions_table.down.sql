@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'session:revoke:all';
+ALTER TABLE refresh_tokens DROP COLUMN session_id;
+DROP TABLE sessions;
@@ -0,0 +1,23 @@
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    user_agent TEXT NULL,
+    ip_address VARCHAR(45) NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_seen_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NULL,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+ALTER TABLE refresh_tokens ADD COLUMN session_id UUID NULL REFERENCES sessions(id) ON DELETE CASCADE;
+
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES ('session:revoke:all', 'session', 'revoke:all', 'Revoke the sessions of any user', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name IN ('system_admin', 'admin') AND p.name = 'session:revoke:all';
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE name = 'key:manage';
+DROP TABLE signing_keys;
@@ -0,0 +1,17 @@
+CREATE TABLE signing_keys (
+    kid VARCHAR(100) PRIMARY KEY,
+    algorithm VARCHAR(10) NOT NULL,
+    private_key TEXT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP NULL,
+    verify_until TIMESTAMP NULL
+);
+
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES ('key:manage', 'key', 'manage', 'List, rotate and retire JWT signing keys', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name = 'system_admin' AND p.name = 'key:manage';
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'mfa:reset';
+DROP TABLE mfa_recovery_codes;
+DROP TABLE user_mfa;
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE name = 'lockout:manage';
+DROP TABLE login_attempts;
@@ -0,0 +1,14 @@
+CREATE TABLE login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP NOT NULL,
+    locked_until TIMESTAMP NULL
+);
+
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES ('lockout:manage', 'lockout', 'manage', 'List and clear login lockouts', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name IN ('system_admin', 'admin') AND p.name = 'lockout:manage';
@@ -0,0 +1,5 @@
+CREATE TABLE rate_limit_buckets (
+    key VARCHAR(400) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
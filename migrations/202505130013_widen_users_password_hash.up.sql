@@ -0,0 +1 @@
+ALTER TABLE users ALTER COLUMN password_hash TYPE VARCHAR(255);
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE name = 'password:force_reset';
+ALTER TABLE users DROP COLUMN must_change_password;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES ('password:force_reset', 'password', 'force_reset', 'Force a user to change their password', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name IN ('system_admin', 'admin') AND p.name = 'password:force_reset';
@@ -0,0 +1,2 @@
+DELETE FROM permissions WHERE name = 'oauth:client:manage';
+DROP TABLE oauth_clients;
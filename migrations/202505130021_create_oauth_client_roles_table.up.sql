@@ -0,0 +1,10 @@
+CREATE TABLE oauth_client_roles (
+    client_id UUID NOT NULL,
+    role_id UUID NOT NULL,
+    assigned_by UUID NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (client_id, role_id),
+    FOREIGN KEY (client_id) REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (assigned_by) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE name = 'role:permission:manage';
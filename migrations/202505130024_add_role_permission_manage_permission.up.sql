@@ -0,0 +1,7 @@
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES ('role:permission:manage', 'role', 'permission:manage', 'Grant and revoke the permissions of roles', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name = 'system_admin' AND p.name = 'role:permission:manage';
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE name IN ('permission:update', 'permission:delete');
@@ -0,0 +1,9 @@
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES
+    ('permission:update', 'permission', 'update', 'Update permissions', NOW(), NOW()),
+    ('permission:delete', 'permission', 'delete', 'Delete permissions the routes do not require', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name IN ('system_admin', 'admin') AND p.name IN ('permission:update', 'permission:delete');
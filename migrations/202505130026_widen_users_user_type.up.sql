@@ -0,0 +1 @@
+ALTER TABLE users ALTER COLUMN user_type TYPE VARCHAR(50);
@@ -0,0 +1,7 @@
+INSERT INTO permissions (name, resource, action, description, created_at, updated_at)
+VALUES ('user:role:manage', 'user', 'role:manage', 'Assign and remove additional roles of users', NOW(), NOW());
+
+INSERT INTO role_permissions (role_id, permission_id, created_at)
+SELECT r.id, p.id, NOW()
+FROM roles r, permissions p
+WHERE r.name IN ('system_admin', 'admin') AND p.name = 'user:role:manage';
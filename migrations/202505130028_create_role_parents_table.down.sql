@@ -0,0 +1,2 @@
+DROP VIEW effective_role_permissions;
+DROP TABLE role_parents;
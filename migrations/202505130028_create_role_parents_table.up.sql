@@ -0,0 +1,24 @@
+CREATE TABLE role_parents (
+    role_id UUID NOT NULL,
+    parent_role_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (role_id, parent_role_id),
+    CHECK (role_id <> parent_role_id),
+    FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    FOREIGN KEY (parent_role_id) REFERENCES roles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_role_parents_parent_role_id ON role_parents(parent_role_id);
+
+-- Permissions of every role including those inherited from its parents, source_role_id is the role that grants it directly
+CREATE VIEW effective_role_permissions AS
+WITH RECURSIVE role_ancestors(role_id, ancestor_id) AS (
+    SELECT id, id FROM roles
+    UNION
+    SELECT ra.role_id, rp.parent_role_id
+    FROM role_ancestors ra
+    JOIN role_parents rp ON rp.role_id = ra.ancestor_id
+)
+SELECT ra.role_id, rp.permission_id, ra.ancestor_id AS source_role_id
+FROM role_ancestors ra
+JOIN role_permissions rp ON rp.role_id = ra.ancestor_id;